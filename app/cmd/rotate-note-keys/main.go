@@ -0,0 +1,99 @@
+// Command rotate-note-keys re-encrypts notes and their revisions with the primary encryption key,
+// so keys they were encrypted with before can be removed from the configuration.
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/illiafox/spy-cat-test-assignment/app/config"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/repository/postgres"
+	"github.com/illiafox/spy-cat-test-assignment/app/pkg/poolwrapper"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type keyRotator interface {
+	RotateKeys(ctx context.Context, batchSize int) (int, error)
+}
+
+func main() {
+	batchSize := flag.Int("batch-size", 100, "number of rows re-encrypted in one transaction")
+	flag.Parse()
+
+	cfg, err := config.New()
+	if err != nil {
+		log.Fatalf("read config: %v", err)
+	}
+
+	logger, err := zap.NewProductionConfig().Build()
+	if err != nil {
+		log.Fatalf("failed to start zap logger: %v", err)
+	}
+
+	defer logger.Sync()
+
+	if *batchSize <= 0 {
+		logger.Fatal("batch size must be positive", zap.Int("batch_size", *batchSize))
+	}
+
+	keyring, err := cfg.Notes.Keyring()
+	if err != nil {
+		logger.Fatal("failed to load notes encryption keys", zap.Error(err))
+	}
+
+	if keyring == nil {
+		logger.Fatal("notes encryption keys are not configured")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, cfg.PostgresURI)
+	if err != nil {
+		logger.Fatal("failed to init pgxpool", zap.Error(err))
+	}
+	defer pool.Close()
+
+	sqlbuilder.DefaultFlavor = sqlbuilder.PostgreSQL
+	db := poolwrapper.NewPool(pool)
+
+	tables := []struct {
+		name    string
+		rotator keyRotator
+	}{
+		{"notes", postgres.NewNotesRepository(db, keyring, false)},
+		{"note_revisions", postgres.NewNoteRevisionsRepository(db, keyring)},
+	}
+
+	for _, table := range tables {
+		var total int
+
+		for {
+			var rotated int
+
+			err = db.TxFunc(ctx, func(ctx context.Context, _ pgx.Tx) error {
+				rotated, err = table.rotator.RotateKeys(ctx, *batchSize)
+				return err
+			})
+			if err != nil {
+				logger.Fatal("failed to rotate keys", zap.String("table", table.name), zap.Error(err))
+			}
+
+			if rotated == 0 {
+				break
+			}
+
+			total += rotated
+			logger.Info("re-encrypted rows", zap.String("table", table.name), zap.Int("total", total))
+		}
+
+		logger.Info("keys rotated", zap.String("table", table.name), zap.Int("rows", total),
+			zap.String("key_id", keyring.PrimaryID()))
+	}
+}
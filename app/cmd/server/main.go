@@ -8,7 +8,9 @@ import (
 	"github.com/gofiber/fiber/v2/log"
 	"github.com/huandu/go-sqlbuilder"
 	"github.com/illiafox/spy-cat-test-assignment/app/config"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/repository/blobs"
 	"github.com/illiafox/spy-cat-test-assignment/app/internal/repository/catapi"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/repository/countries"
 	"github.com/illiafox/spy-cat-test-assignment/app/internal/repository/postgres"
 	"github.com/illiafox/spy-cat-test-assignment/app/internal/service"
 	"github.com/illiafox/spy-cat-test-assignment/app/internal/transport/http"
@@ -51,26 +53,56 @@ func main() {
 		logger.Fatal("failed to run migrations", zap.Error(err))
 	}
 
+	notesKeyring, err := cfg.Notes.Keyring()
+	if err != nil {
+		logger.Fatal("failed to load notes encryption keys", zap.Error(err))
+	}
+
 	sqlbuilder.DefaultFlavor = sqlbuilder.PostgreSQL
 	db := poolwrapper.NewPool(pool)
 	catsRepository := postgres.NewCatsRepository(db)
 	missionsRepository := postgres.NewMissionsRepository(db)
 	targetsRepository := postgres.NewTargetsRepository(db)
-	notesRepository := postgres.NewNotesRepository(db)
+	notesRepository := postgres.NewNotesRepository(db, notesKeyring, cfg.Notes.EncryptedSearch)
+	noteRevisionsRepository := postgres.NewNoteRevisionsRepository(db, notesKeyring)
+	noteAttachmentsRepository := postgres.NewNoteAttachmentsRepository(db)
+	eventsRepository := postgres.NewEventsRepository(db)
+	personsRepository := postgres.NewPersonsRepository(db)
+	assignmentsRepository := postgres.NewAssignmentsRepository(db)
 
 	catBreedChecker, err := catapi.NewClient()
 	if err != nil {
 		logger.Fatal("failed to create catapi client", zap.Error(err))
 	}
 
+	countryDirectory, err := countries.NewDirectory()
+	if err != nil {
+		logger.Fatal("failed to load countries directory", zap.Error(err))
+	}
+
+	blobStore, err := blobs.NewLocalStore(cfg.Attachments.Dir)
+	if err != nil {
+		logger.Fatal("failed to create attachments blob store", zap.Error(err))
+	}
+
 	//
 
 	service := service.NewService(
+		cfg.Rules,
+		cfg.Notes,
+		cfg.Attachments,
 		catBreedChecker,
+		countryDirectory,
 		catsRepository,
 		missionsRepository,
 		targetsRepository,
 		notesRepository,
+		noteRevisionsRepository,
+		noteAttachmentsRepository,
+		blobStore,
+		eventsRepository,
+		personsRepository,
+		assignmentsRepository,
 		catsRepository,
 	)
 
@@ -1,6 +1,12 @@
 package config
 
-import "github.com/ilyakaznacheev/cleanenv"
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/pkg/envelope"
+	"github.com/ilyakaznacheev/cleanenv"
+)
 
 type Config struct {
 	ServerHost        string `env:"SERVER_HOST"   env-default:"0.0.0.0"`
@@ -8,6 +14,128 @@ type Config struct {
 	PostgresURI       string `env:"POSTGRES_URI"  env-required:""`
 	Debug             bool   `env:"DEBUG"`
 	DisableStacktrace bool   `env:"NO_STACKTRACE"`
+	AdminToken        string `env:"ADMIN_TOKEN"`
+	Rules             Rules
+	Notes             Notes
+	Attachments       Attachments
+}
+
+type Notes struct {
+	// latest notes embedded into each target in responses, 0 means unlimited
+	EmbedLimit int `env:"NOTES_EMBED_LIMIT" env-default:"10"`
+
+	// base64 encoded 32-byte master keys by their ids, as id:key pairs separated by commas.
+	// Notes are encrypted with the EncryptionKeyID one, the rest are kept to decrypt older notes.
+	// Notes are stored in plain text if no keys are set.
+	EncryptionKeys  map[string]string `env:"NOTES_ENCRYPTION_KEYS"`
+	EncryptionKeyID string            `env:"NOTES_ENCRYPTION_KEY_ID"`
+
+	// encrypted notes are not indexed, so searching them decrypts every note matching the filters
+	// and sends the contents to the database to be matched. If disabled, search is unavailable with encryption.
+	EncryptedSearch bool `env:"NOTES_ENCRYPTED_SEARCH" env-default:"true"`
+}
+
+func (n Notes) Validate() error {
+	if n.EmbedLimit < 0 {
+		return fmt.Errorf("embed limit must not be negative, got %d", n.EmbedLimit)
+	}
+
+	if _, err := n.Keyring(); err != nil {
+		return fmt.Errorf("encryption keys: %w", err)
+	}
+
+	return nil
+}
+
+func (n Notes) decodeEncryptionKeys() (map[string][]byte, error) {
+	keys := make(map[string][]byte, len(n.EncryptionKeys))
+
+	for id, encoded := range n.EncryptionKeys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("decode encryption key '%s': %w", id, err)
+		}
+
+		if len(key) != envelope.KeySize {
+			return nil, fmt.Errorf("encryption key '%s' must be %d bytes long, got %d", id, envelope.KeySize, len(key))
+		}
+
+		keys[id] = key
+	}
+
+	return keys, nil
+}
+
+// Keyring returns nil if encryption keys are not set
+func (n Notes) Keyring() (*envelope.Keyring, error) {
+	if len(n.EncryptionKeys) == 0 {
+		return nil, nil
+	}
+
+	keys, err := n.decodeEncryptionKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	return envelope.NewKeyring(keys, n.EncryptionKeyID)
+}
+
+// Attachments configure files attached to notes, stored in the local directory
+type Attachments struct {
+	Dir     string `env:"ATTACHMENTS_DIR"      env-default:"attachments"`
+	MaxSize int64  `env:"ATTACHMENTS_MAX_SIZE" env-default:"10485760"` // bytes
+	// allowed media types, detected from the contents
+	ContentTypes []string `env:"ATTACHMENTS_CONTENT_TYPES" env-default:"image/jpeg,image/png,image/gif,image/webp,application/pdf,text/plain"`
+}
+
+func (a Attachments) Validate() error {
+	if a.MaxSize < 1 {
+		return fmt.Errorf("max size must be positive, got %d", a.MaxSize)
+	}
+
+	return nil
+}
+
+// Rules are business rules of missions, shared by request validation and service
+type Rules struct {
+	MinTargets              int  `env:"RULES_MIN_TARGETS"                env-default:"1"`
+	MaxTargets              int  `env:"RULES_MAX_TARGETS"                env-default:"3"`
+	MaxOpenMissionsPerCat   int  `env:"RULES_MAX_OPEN_MISSIONS_PER_CAT"  env-default:"1"` // 0 means unlimited
+	FreezeNotesOnCompletion bool `env:"RULES_FREEZE_NOTES_ON_COMPLETION" env-default:"true"`
+	// min experience of cats assigned to missions having high-risk targets, 0 disables the rule
+	HighRiskMinExperienceYears int `env:"RULES_HIGH_RISK_MIN_EXPERIENCE_YEARS" env-default:"5"`
+
+	// completion policy: notes a target must have and whether a closing report must be submitted on completion
+	CompletionMinNotes      int  `env:"RULES_COMPLETION_MIN_NOTES"      env-default:"0"`
+	CompletionRequireReport bool `env:"RULES_COMPLETION_REQUIRE_REPORT" env-default:"false"`
+}
+
+func (r Rules) Validate() error {
+	if r.MinTargets < 1 {
+		return fmt.Errorf("min targets must be at least 1, got %d", r.MinTargets)
+	}
+
+	if r.MaxTargets < r.MinTargets {
+		return fmt.Errorf("max targets (%d) must not be less than min targets (%d)", r.MaxTargets, r.MinTargets)
+	}
+
+	if r.MaxOpenMissionsPerCat < 0 {
+		return fmt.Errorf("max open missions per cat must not be negative, got %d", r.MaxOpenMissionsPerCat)
+	}
+
+	if r.HighRiskMinExperienceYears < 0 {
+		return fmt.Errorf("high risk min experience years must not be negative, got %d", r.HighRiskMinExperienceYears)
+	}
+
+	if r.CompletionMinNotes < 0 {
+		return fmt.Errorf("completion min notes must not be negative, got %d", r.CompletionMinNotes)
+	}
+
+	return nil
+}
+
+func (r Rules) TargetsCountAllowed(count int) bool {
+	return count >= r.MinTargets && count <= r.MaxTargets
 }
 
 func New() (*Config, error) {
@@ -18,5 +146,17 @@ func New() (*Config, error) {
 		return nil, err
 	}
 
+	if err = cfg.Rules.Validate(); err != nil {
+		return nil, fmt.Errorf("rules: %w", err)
+	}
+
+	if err = cfg.Notes.Validate(); err != nil {
+		return nil, fmt.Errorf("notes: %w", err)
+	}
+
+	if err = cfg.Attachments.Validate(); err != nil {
+		return nil, fmt.Errorf("attachments: %w", err)
+	}
+
 	return cfg, nil
 }
@@ -8,8 +8,25 @@ const (
 	CatNotFound               Code = "CAT_NOT_FOUND"
 	MissionNotFound           Code = "MISSION_NOT_FOUND"
 	TargetNotFound            Code = "TARGET_NOT_FOUND"
+	PersonNotFound            Code = "PERSON_NOT_FOUND"
+	NoteNotFound              Code = "NOTE_NOT_FOUND"
+	NoteRevisionNotFound      Code = "NOTE_REVISION_NOT_FOUND"
+	NoteAttachmentNotFound    Code = "NOTE_ATTACHMENT_NOT_FOUND"
+	AttachmentTooLarge        Code = "ATTACHMENT_TOO_LARGE"
+	UnsupportedAttachmentType Code = "UNSUPPORTED_ATTACHMENT_TYPE"
 	MissionAlreadyCompleted   Code = "MISSION_ALREADY_COMPLETED"
 	CatAlreadyAssigned        Code = "CAT_ALREADY_ASSIGNED"
 	TargetAlreadyCompleted    Code = "TARGET_ALREADY_COMPLETED"
 	AllTargetsAreNotCompleted Code = "ALL_TARGETS_ARE_NOT_COMPLETED"
+	NoCandidatesAvailable     Code = "NO_CANDIDATES_AVAILABLE"
+	MissionNotCompleted       Code = "MISSION_NOT_COMPLETED"
+	TargetNotCompleted        Code = "TARGET_NOT_COMPLETED"
+	PermissionDenied          Code = "PERMISSION_DENIED"
+	CatIsBusy                 Code = "CAT_IS_BUSY"
+	CatDoubleBooked           Code = "CAT_DOUBLE_BOOKED"
+	CatNotExperienced         Code = "CAT_NOT_EXPERIENCED"
+	NoteAuthorNotAssigned     Code = "NOTE_AUTHOR_NOT_ASSIGNED"
+	DuplicateTarget           Code = "DUPLICATE_TARGET"
+	CompletionPolicyNotMet    Code = "COMPLETION_POLICY_NOT_MET"
+	SearchUnavailable         Code = "SEARCH_UNAVAILABLE"
 )
@@ -51,6 +51,22 @@ func New(code codes.Code, message error) *Error {
 	}
 }
 
+// HasCode reports whether err is an *Error with one of the codes
+func HasCode(err error, codes ...codes.Code) bool {
+	var e *Error
+	if !errors.As(err, &e) {
+		return false
+	}
+
+	for _, code := range codes {
+		if e.Code == code {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Common predefined errors
 
 func Internal(message error) *Error {
@@ -73,6 +89,30 @@ func TargetNotFound(target int) *Error {
 	return New(codes.TargetNotFound, fmt.Errorf("target with id '%d' was not found", target))
 }
 
+func PersonNotFound(personID int) *Error {
+	return New(codes.PersonNotFound, fmt.Errorf("person with id '%d' was not found", personID))
+}
+
+func NoteNotFound(noteID int) *Error {
+	return New(codes.NoteNotFound, fmt.Errorf("note with id '%d' was not found", noteID))
+}
+
+func NoteRevisionNotFound(noteID, revision int) *Error {
+	return New(codes.NoteRevisionNotFound, fmt.Errorf("revision %d of note with id '%d' was not found", revision, noteID))
+}
+
+func NoteAttachmentNotFound(attachmentID int) *Error {
+	return New(codes.NoteAttachmentNotFound, fmt.Errorf("attachment with id '%d' was not found", attachmentID))
+}
+
+func AttachmentTooLarge(size, maxSize int64) *Error {
+	return New(codes.AttachmentTooLarge, fmt.Errorf("attachment size %d bytes exceeds the limit of %d bytes", size, maxSize))
+}
+
+func UnsupportedAttachmentType(contentType string) *Error {
+	return New(codes.UnsupportedAttachmentType, fmt.Errorf("attachments of type '%s' are not allowed", contentType))
+}
+
 func InvalidCatBreed(breed string, reason string) *Error {
 	return New(codes.InvalidRequest, fmt.Errorf("cat's breed '%s' is invalid: %s", breed, reason))
 }
@@ -99,3 +139,71 @@ func TargetAlreadyCompleted(targetID int) *Error {
 func AllTargetsAreNotCompleted() *Error {
 	return New(codes.AllTargetsAreNotCompleted, errors.New("all targets are not completed"))
 }
+
+func CatIsBusy(catID, maxOpenMissions int) *Error {
+	return New(codes.CatIsBusy, fmt.Errorf(
+		"cat with id '%d' already has maximum number of open missions (%d)",
+		catID, maxOpenMissions,
+	))
+}
+
+func CatDoubleBooked(catID, missionID int) *Error {
+	return New(codes.CatDoubleBooked, fmt.Errorf(
+		"cat with id '%d' is already assigned to mission with id '%d' scheduled for overlapping period",
+		catID, missionID,
+	))
+}
+
+func NoteAuthorNotAssigned(catID, missionID int) *Error {
+	return New(codes.NoteAuthorNotAssigned, fmt.Errorf(
+		"cat with id '%d' is not assigned to mission with id '%d' and can't add notes to its targets",
+		catID, missionID,
+	))
+}
+
+func CatNotExperienced(catID, minExperienceYears int) *Error {
+	return New(codes.CatNotExperienced, fmt.Errorf(
+		"cat with id '%d' must have at least %d years of experience to handle high-risk targets",
+		catID, minExperienceYears,
+	))
+}
+
+func DuplicateTarget(targetIDs []int) *Error {
+	return New(codes.DuplicateTarget, fmt.Errorf(
+		"mission already has targets with the same name and country: %v", targetIDs,
+	)).WithMetadata("target_ids", targetIDs)
+}
+
+func DuplicateNewTargets(indexes []int) *Error {
+	return New(codes.DuplicateTarget, fmt.Errorf(
+		"new targets at indexes %v have the same name and country", indexes,
+	)).WithMetadata("indexes", indexes)
+}
+
+func CompletionPolicyNotMet(targetID int, reason string) *Error {
+	return New(codes.CompletionPolicyNotMet, fmt.Errorf("target with id '%d' can't be completed: %s", targetID, reason))
+}
+
+func InvalidMissionSchedule() *Error {
+	return New(codes.InvalidRequest, errors.New("mission must end after it starts"))
+}
+
+func NoCandidatesAvailable(missionID int) *Error {
+	return New(codes.NoCandidatesAvailable, fmt.Errorf("no cats are available for mission with id '%d'", missionID))
+}
+
+func MissionNotCompleted(missionID int) *Error {
+	return New(codes.MissionNotCompleted, fmt.Errorf("mission with id '%d' is not completed", missionID))
+}
+
+func TargetNotCompleted(targetID int) *Error {
+	return New(codes.TargetNotCompleted, fmt.Errorf("target with id '%d' is not completed", targetID))
+}
+
+func PermissionDenied(reason string) *Error {
+	return New(codes.PermissionDenied, fmt.Errorf("permission denied: %s", reason))
+}
+
+func SearchUnavailable(reason string) *Error {
+	return New(codes.SearchUnavailable, fmt.Errorf("search is unavailable: %s", reason))
+}
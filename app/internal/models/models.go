@@ -15,36 +15,266 @@ type Cat struct {
 }
 
 type Mission struct {
-	ID            int       `db:"id"`
-	AssignedCatID *int      `db:"assigned_cat_id"`
-	IsCompleted   bool      `db:"is_completed"`
-	CreatedAt     time.Time `db:"created_at"`
-	UpdatedAt     time.Time `db:"updated_at"`
+	ID            int        `db:"id"`
+	AssignedCatID *int       `db:"assigned_cat_id"`
+	IsCompleted   bool       `db:"is_completed"`
+	Budget        *int       `db:"budget"`
+	StartsAt      *time.Time `db:"starts_at"`
+	EndsAt        *time.Time `db:"ends_at"`
+	AssignedAt    *time.Time `db:"assigned_at"`
+	CompletedAt   *time.Time `db:"completed_at"`
+	ReopenedAt    *time.Time `db:"reopened_at"`
+	ReopenedBy    *string    `db:"reopened_by"`
+	ReopenReason  *string    `db:"reopen_reason"`
+	CreatedAt     time.Time  `db:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at"`
 }
 
 type MissionFull struct {
 	*Mission
-	Targets []*Target
+	Cat     *Cat
+	Targets []*TargetFull
+	Cost    *MissionCost
+	// RiskLevel is the highest risk level among mission's targets
+	RiskLevel RiskLevel
+}
+
+// MissionCost sums costs of every cat ever assigned to the mission,
+// MonthlySalary is the salary of the currently assigned one
+type MissionCost struct {
+	MissionID     int
+	AssignedCatID *int
+	MonthlySalary int
+	Days          float64
+	Cost          int
+	Budget        *int
+	OverBudget    bool
+	Assignments   []*AssignmentCost
+}
+
+type AssignmentCost struct {
+	CatID         int
+	MonthlySalary int
+	Days          float64
+	Cost          int
 }
 
 type Target struct {
+	ID           int        `db:"id"`
+	MissionID    int        `db:"mission_id"`
+	IsCompleted  bool       `db:"is_completed"`
+	Name         string     `db:"name"`
+	Country      string     `db:"country"`
+	City         *string    `db:"city"`
+	PersonID     *int       `db:"person_id"`
+	RiskLevel    RiskLevel  `db:"risk_level"`
+	Priority     int        `db:"priority"`
+	Latitude     *float64   `db:"latitude"`
+	Longitude    *float64   `db:"longitude"`
+	ReopenedAt   *time.Time `db:"reopened_at"`
+	ReopenedBy   *string    `db:"reopened_by"`
+	ReopenReason *string    `db:"reopen_reason"`
+	CreatedAt    time.Time  `db:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at"`
+
+	// CountryInfo is filled from the countries directory, nil for unknown codes
+	CountryInfo *Country `db:"-"`
+}
+
+type RiskLevel string
+
+const (
+	RiskLevelLow    RiskLevel = "low"
+	RiskLevelMedium RiskLevel = "medium"
+	RiskLevelHigh   RiskLevel = "high"
+)
+
+type NearbyTarget struct {
+	*Target
+	DistanceKm float64
+}
+
+type TargetLocationSource string
+
+const (
+	TargetLocationCoordinates     TargetLocationSource = "coordinates"
+	TargetLocationCountryCentroid TargetLocationSource = "country_centroid"
+)
+
+// TargetLocation is a target placed on the map, Latitude and Longitude are nil when it can't be located
+type TargetLocation struct {
+	*Target
+	Mission   *Mission
+	Latitude  *float64
+	Longitude *float64
+	Source    TargetLocationSource
+}
+
+type Country struct {
+	Code      string
+	Name      string
+	Region    string
+	Latitude  float64
+	Longitude float64
+}
+
+type CountryStats struct {
+	Country           string `db:"country"`
+	OpenTargets       int    `db:"open_targets"`
+	CompletedTargets  int    `db:"completed_targets"`
+	OpenMissions      int    `db:"open_missions"`
+	CompletedMissions int    `db:"completed_missions"`
+
+	CountryInfo *Country `db:"-"`
+}
+
+// SimilarTarget is an existing target having the same normalized name and country
+// as the new target at KeyIndex
+type SimilarTarget struct {
+	*Target
+	KeyIndex int
+}
+
+type Person struct {
+	ID        int
+	Name      string
+	Country   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Dossier is a person with every target occurrence across missions
+type Dossier struct {
+	*Person
+	Targets []*TargetFull
+}
+
+// TargetFull is a target with its latest notes, NotesCount counts all of them
+type TargetFull struct {
+	*Target
+	Notes      []*Note
+	NotesCount int
+}
+
+type Note struct {
 	ID          int       `db:"id"`
 	MissionID   int       `db:"mission_id"`
-	IsCompleted bool      `db:"is_completed"`
-	Name        string    `db:"name"`
-	Country     string    `db:"country"`
+	TargetID    int       `db:"target_id"`
+	AuthorCatID *int      `db:"author_cat_id"`
+	Content     string    `db:"content"`
 	CreatedAt   time.Time `db:"created_at"`
 	UpdatedAt   time.Time `db:"updated_at"`
 }
 
-type TargetFull struct {
-	*Target
-	Notes []*Note
+type NotesCount struct {
+	MissionID int
+	TargetID  int
+	Count     int
 }
 
-type Note struct {
-	ID        int       `db:"id"`
-	TargetID  int       `db:"target_id"`
-	Content   string    `db:"content"`
-	CreatedAt time.Time `db:"created_at"`
+// NotesCursor is the position in the list of notes ordered by creation time and id.
+// It does not refer to the note itself, so the cursor stays valid after the note is deleted
+type NotesCursor struct {
+	CreatedAt time.Time
+	ID        int
+}
+
+// NotesPage is a page of notes, the newest first; NextBefore is the cursor of the next page, nil on the last one
+type NotesPage struct {
+	Notes      []*Note
+	NextBefore *NotesCursor
+}
+
+// NoteAttachment is metadata of a file attached to the note, its contents are kept in the blob store by StorageKey
+type NoteAttachment struct {
+	ID          int
+	NoteID      int
+	MissionID   int
+	TargetID    int
+	FileName    string
+	ContentType string
+	Size        int64
+	StorageKey  string
+	CreatedAt   time.Time
+}
+
+// NoteSearchResult is a note matching the search query, Snippet has matches marked with <mark> tags
+type NoteSearchResult struct {
+	*Note
+	TargetName string
+	Country    string
+	Rank       float64
+	Snippet    string
+}
+
+type NoteRevision struct {
+	ID        int
+	NoteID    int
+	MissionID int
+	TargetID  int
+	Revision  int
+	Content   string
+	Editor    string
+	CreatedAt time.Time
+}
+
+// CatStats is the cat's track record: CountryMissions counts completed missions the cat was ever assigned to,
+// CountryMissionsCompleted counts ones the cat was assigned to at the moment of completion
+type CatStats struct {
+	CatID                    int `db:"cat_id"`
+	CountryMissions          int `db:"country_missions"`
+	CountryMissionsCompleted int `db:"country_missions_completed"`
+}
+
+type Candidate struct {
+	*Cat
+	Score        float64
+	SuccessRate  float64
+	OpenMissions int
+}
+
+type EventType string
+
+const (
+	EventMissionCreated    EventType = "mission_created"
+	EventMissionUpdated    EventType = "mission_updated"
+	EventCatAssigned       EventType = "cat_assigned"
+	EventCatUnassigned     EventType = "cat_unassigned"
+	EventMissionCompleted  EventType = "mission_completed"
+	EventTargetsAdded      EventType = "targets_added"
+	EventTargetUpdated     EventType = "target_updated"
+	EventTargetCompleted   EventType = "target_completed"
+	EventTargetDeleted     EventType = "target_deleted"
+	EventNotesAdded        EventType = "notes_added"
+	EventMissionReopened   EventType = "mission_reopened"
+	EventTargetReopened    EventType = "target_reopened"
+	EventNoteUpdated       EventType = "note_updated"
+	EventNoteDeleted       EventType = "note_deleted"
+	EventAttachmentAdded   EventType = "attachment_added"
+	EventAttachmentDeleted EventType = "attachment_deleted"
+)
+
+type Event struct {
+	ID        int            `db:"id"`
+	MissionID int            `db:"mission_id"`
+	Type      EventType      `db:"type"`
+	Actor     string         `db:"actor"`
+	Payload   map[string]any `db:"payload"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+// Assignment is kept after the cat is deleted, so it keeps cat's name and salary at assignment time
+type Assignment struct {
+	ID           int        `db:"id"`
+	MissionID    int        `db:"mission_id"`
+	CatID        int        `db:"cat_id"`
+	CatName      string     `db:"cat_name"`
+	CatSalary    int        `db:"cat_salary"`
+	AssignedAt   time.Time  `db:"assigned_at"`
+	UnassignedAt *time.Time `db:"unassigned_at"`
+}
+
+type CatSchedule struct {
+	Current  []*MissionFull
+	Upcoming []*MissionFull
 }
@@ -0,0 +1,100 @@
+package blobs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/apperrors"
+)
+
+// LocalStore keeps blobs as files in the directory, keys are slash-separated paths relative to it
+type LocalStore struct {
+	dir string
+}
+
+func NewLocalStore(dir string) (*LocalStore, error) {
+	err := os.MkdirAll(dir, 0o750)
+	if err != nil {
+		return nil, fmt.Errorf("create directory %s: %w", dir, err)
+	}
+
+	return &LocalStore{dir: dir}, nil
+}
+
+// Put writes the blob to a temporary file first, so readers never see partially written blobs
+func (s *LocalStore) Put(_ context.Context, key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o750)
+	if err != nil {
+		return apperrors.Internal(err).Wrap("create blob directory")
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return apperrors.Internal(err).Wrap("create temporary file")
+	}
+	defer os.Remove(file.Name())
+
+	_, err = io.Copy(file, r)
+	if err != nil {
+		file.Close()
+		return apperrors.Internal(err).Wrap("write blob").WithMetadata("key", key)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return apperrors.Internal(err).Wrap("close blob file").WithMetadata("key", key)
+	}
+
+	err = os.Rename(file.Name(), path)
+	if err != nil {
+		return apperrors.Internal(err).Wrap("rename blob file").WithMetadata("key", key)
+	}
+
+	return nil
+}
+
+func (s *LocalStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("open blob").WithMetadata("key", key)
+	}
+
+	return file, nil
+}
+
+// Delete removes the blob, missing blobs are ignored
+func (s *LocalStore) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return apperrors.Internal(err).Wrap("remove blob").WithMetadata("key", key)
+	}
+
+	return nil
+}
+
+func (s *LocalStore) path(key string) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(key)) || strings.HasPrefix(filepath.Base(key), ".") {
+		return "", apperrors.Internal(fmt.Errorf("invalid blob key '%s'", key))
+	}
+
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
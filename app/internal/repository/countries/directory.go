@@ -0,0 +1,55 @@
+package countries
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+)
+
+// countries.json holds ISO 3166-1 alpha-2 codes with the region and the geographic centroid of each country
+//
+//go:embed countries.json
+var countriesFile []byte
+
+type country struct {
+	Code      string  `json:"code"`
+	Name      string  `json:"name"`
+	Region    string  `json:"region"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type Directory struct {
+	Countries map[string]*models.Country
+}
+
+func NewDirectory() (*Directory, error) {
+	var countries []country
+
+	err := json.Unmarshal(countriesFile, &countries)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshall countries from file: %w", err)
+	}
+
+	directory := &Directory{}
+
+	directory.Countries = make(map[string]*models.Country, len(countries))
+	for _, c := range countries {
+		directory.Countries[c.Code] = &models.Country{
+			Code:      c.Code,
+			Name:      c.Name,
+			Region:    c.Region,
+			Latitude:  c.Latitude,
+			Longitude: c.Longitude,
+		}
+	}
+
+	return directory, nil
+}
+
+func (d Directory) Country(code string) (*models.Country, bool) {
+	c, ok := d.Countries[code]
+	return c, ok
+}
@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/apperrors"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/repository/postgres/schema"
+	"github.com/illiafox/spy-cat-test-assignment/app/pkg/poolwrapper"
+	"github.com/jackc/pgx/v5"
+)
+
+type AssignmentsRepository struct {
+	db *poolwrapper.Pool
+}
+
+func NewAssignmentsRepository(db *poolwrapper.Pool) *AssignmentsRepository {
+	return &AssignmentsRepository{db: db}
+}
+
+// Open records the assignment along with cat's current name and salary
+func (r *AssignmentsRepository) Open(ctx context.Context, missionID, catID int) error {
+	const query = `INSERT INTO mission_assignments(mission_id, cat_id, cat_name, cat_salary)
+		SELECT $1, id, name, salary FROM cats WHERE id = $2`
+
+	res, err := r.db.Exec(ctx, query, missionID, catID)
+	if err != nil {
+		return apperrors.Internal(err).Wrap("open assignment: pgx: exec").
+			WithMetadata("query", query).
+			WithMetadata("mission_id", missionID).
+			WithMetadata("cat_id", catID)
+	}
+
+	if res.RowsAffected() == 0 {
+		return apperrors.CatNotFound(catID)
+	}
+
+	return nil
+}
+
+// Close marks the current assignment of the mission as finished, if there is one
+func (r *AssignmentsRepository) Close(ctx context.Context, missionID int) error {
+	const query = `UPDATE mission_assignments SET unassigned_at = NOW()
+		WHERE mission_id = $1 AND unassigned_at IS NULL`
+
+	_, err := r.db.Exec(ctx, query, missionID)
+	if err != nil {
+		return apperrors.Internal(err).Wrap("close assignment: pgx: exec").
+			WithMetadata("query", query).
+			WithMetadata("mission_id", missionID)
+	}
+
+	return nil
+}
+
+// CloseByCat marks current assignments of the cat as finished, returning ids of their missions
+func (r *AssignmentsRepository) CloseByCat(ctx context.Context, catID int) (missionIDs []int, err error) {
+	const query = `UPDATE mission_assignments SET unassigned_at = NOW()
+		WHERE cat_id = $1 AND unassigned_at IS NULL RETURNING mission_id`
+
+	rows, err := r.db.Query(ctx, query, catID)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("close assignments: pgx: query").
+			WithMetadata("query", query).
+			WithMetadata("cat_id", catID)
+	}
+
+	missionIDs, err = pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectRows")
+	}
+
+	return missionIDs, nil
+}
+
+func (r *AssignmentsRepository) AllByMission(ctx context.Context, missionID int) ([]*models.Assignment, error) {
+	const query = `SELECT id, mission_id, cat_id, cat_name, cat_salary, assigned_at, unassigned_at
+		FROM mission_assignments WHERE mission_id = $1 ORDER BY assigned_at, id`
+
+	return r.all(ctx, query, missionID)
+}
+
+func (r *AssignmentsRepository) AllByCat(ctx context.Context, catID int) ([]*models.Assignment, error) {
+	const query = `SELECT id, mission_id, cat_id, cat_name, cat_salary, assigned_at, unassigned_at
+		FROM mission_assignments WHERE cat_id = $1 ORDER BY assigned_at, id`
+
+	return r.all(ctx, query, catID)
+}
+
+func (r *AssignmentsRepository) AllByMissions(ctx context.Context, missionIDs []int) ([]*models.Assignment, error) {
+	const query = `SELECT id, mission_id, cat_id, cat_name, cat_salary, assigned_at, unassigned_at
+		FROM mission_assignments WHERE mission_id = ANY($1) ORDER BY assigned_at, id`
+
+	return r.all(ctx, query, missionIDs)
+}
+
+func (r *AssignmentsRepository) all(ctx context.Context, query string, args ...any) ([]*models.Assignment, error) {
+	var schemaAssignments []schema.Assignment
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("pgx: query").
+			WithMetadata("query", query).
+			WithMetadata("args", args)
+	}
+
+	schemaAssignments, err = pgx.CollectRows(rows, pgx.RowToStructByName[schema.Assignment])
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectRows")
+	}
+
+	assignments := make([]*models.Assignment, len(schemaAssignments))
+	for i := range schemaAssignments {
+		assignments[i] = schemaAssignments[i].ToModel()
+	}
+
+	return assignments, nil
+}
@@ -96,6 +96,26 @@ func (r *CatsRepository) Update(ctx context.Context, params dto.UpdateCatParams)
 	return nil
 }
 
+// Lock locks the cat row until the end of the transaction
+func (r *CatsRepository) Lock(ctx context.Context, catID int) error {
+	const query = `SELECT id FROM cats WHERE id = $1 FOR UPDATE`
+
+	var id int
+
+	err := r.db.QueryRow(ctx, query, catID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return apperrors.CatNotFound(catID)
+		}
+
+		return apperrors.Internal(err).Wrap("pgx: query row").
+			WithMetadata("query", query).
+			WithMetadata("cat_id", catID)
+	}
+
+	return nil
+}
+
 func (r *CatsRepository) One(ctx context.Context, catID int) (*models.Cat, error) {
 	var cat schema.Cat
 
@@ -145,3 +165,65 @@ func (r *CatsRepository) All(ctx context.Context) ([]*models.Cat, error) {
 
 	return cats, nil
 }
+
+// ByIDs returns cats with the given ids, missing cats are skipped
+func (r *CatsRepository) ByIDs(ctx context.Context, catIDs []int) ([]*models.Cat, error) {
+	var schemaCats []schema.Cat
+
+	const query = `SELECT id, name, experience_years, breed, salary, created_at, updated_at
+		FROM cats WHERE id = ANY($1)`
+
+	rows, err := r.db.Query(ctx, query, catIDs)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("pgx: query").
+			WithMetadata("query", query).
+			WithMetadata("cat_ids", catIDs)
+	}
+
+	schemaCats, err = pgx.CollectRows(rows, pgx.RowToStructByName[schema.Cat])
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectRows")
+	}
+
+	cats := make([]*models.Cat, len(schemaCats))
+	for i := range schemaCats {
+		cats[i] = schemaCats[i].ToModel()
+	}
+
+	return cats, nil
+}
+
+// Stats returns track record of every cat through its assignments history, counting only completed missions
+// having targets located in the given countries. A mission is completed by the cat assigned at the moment of completion.
+func (r *CatsRepository) Stats(ctx context.Context, countries []string) ([]*models.CatStats, error) {
+	var schemaStats []schema.CatStats
+
+	const query = `SELECT c.id AS cat_id,
+		COUNT(DISTINCT m.id) AS country_missions,
+		COUNT(DISTINCT m.id) FILTER (WHERE a.unassigned_at IS NULL OR a.unassigned_at >= m.completed_at)
+			AS country_missions_completed
+		FROM cats c
+		LEFT JOIN mission_assignments a ON a.cat_id = c.id
+		LEFT JOIN missions m ON m.id = a.mission_id AND m.is_completed
+			AND EXISTS (SELECT 1 FROM targets t WHERE t.mission_id = m.id AND t.country = ANY($1))
+		GROUP BY c.id`
+
+	rows, err := r.db.Query(ctx, query, countries)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("pgx: query").
+			WithMetadata("query", query).
+			WithMetadata("countries", countries)
+	}
+
+	schemaStats, err = pgx.CollectRows(rows, pgx.RowToStructByName[schema.CatStats])
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectRows")
+	}
+
+	stats := make([]*models.CatStats, len(schemaStats))
+	for i := range schemaStats {
+		stats[i] = schemaStats[i].ToModel()
+	}
+
+	return stats, nil
+}
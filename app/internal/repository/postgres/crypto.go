@@ -0,0 +1,189 @@
+package postgres
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/apperrors"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/repository/postgres/schema"
+	"github.com/illiafox/spy-cat-test-assignment/app/pkg/envelope"
+	"github.com/illiafox/spy-cat-test-assignment/app/pkg/poolwrapper"
+	"github.com/jackc/pgx/v5"
+)
+
+// contentCipher encrypts contents of notes and their revisions with the keyring.
+// Contents are kept in plain text if the keyring is nil.
+//
+// Ciphertexts are bound to the note by its id, so they can't be moved to another note.
+// Revisions keep contents of their note, so they are bound to it as well.
+type contentCipher struct {
+	keyring *envelope.Keyring
+}
+
+// contentAAD is the additional data contents of the note are authenticated with
+func contentAAD(noteID int) []byte {
+	return []byte("notes/" + strconv.Itoa(noteID))
+}
+
+// encrypt seals the content of the note with the primary key, ciphertext is base64 encoded to fit the text column
+func (c contentCipher) encrypt(noteID int, content string) (schema.EncryptedContent, error) {
+	if c.keyring == nil {
+		return schema.EncryptedContent{Content: content}, nil
+	}
+
+	sealed, err := c.keyring.Seal([]byte(content), contentAAD(noteID))
+	if err != nil {
+		return schema.EncryptedContent{}, apperrors.Internal(err).Wrap("encrypt content")
+	}
+
+	return fromSealed(sealed), nil
+}
+
+// decrypt replaces the stored content of the note with the plain text one
+func (c contentCipher) decrypt(noteID int, content *schema.EncryptedContent) error {
+	if content.KeyID == nil {
+		return nil
+	}
+
+	if c.keyring == nil {
+		return apperrors.Internal(errors.New("content is encrypted, but encryption keys are not configured")).
+			WithMetadata("key_id", *content.KeyID)
+	}
+
+	sealed, err := toSealed(*content)
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := c.keyring.Open(sealed, contentAAD(noteID))
+	if err != nil {
+		return apperrors.Internal(err).Wrap("decrypt content").WithMetadata("key_id", *content.KeyID)
+	}
+
+	*content = schema.EncryptedContent{Content: string(plaintext)}
+
+	return nil
+}
+
+// rotate encrypts plain text content with the primary key, or re-wraps the data key of encrypted one with it
+func (c contentCipher) rotate(noteID int, content schema.EncryptedContent) (schema.EncryptedContent, error) {
+	if content.KeyID == nil {
+		return c.encrypt(noteID, content.Content)
+	}
+
+	sealed, err := toSealed(content)
+	if err != nil {
+		return schema.EncryptedContent{}, err
+	}
+
+	rewrapped, err := c.keyring.Rewrap(sealed)
+	if err != nil {
+		return schema.EncryptedContent{}, apperrors.Internal(err).Wrap("rewrap data key").
+			WithMetadata("key_id", *content.KeyID)
+	}
+
+	return fromSealed(rewrapped), nil
+}
+
+// redactedContent replaces contents of notes in query args attached to errors, so they don't get into logs
+const redactedContent = "[REDACTED]"
+
+// redactContents returns a copy of args with the contents, in plain text or encrypted, replaced by redactedContent
+func redactContents(args []any, contents ...string) []any {
+	redacted := make([]any, len(args))
+	for i, arg := range args {
+		redacted[i] = arg
+
+		if s, ok := arg.(string); ok && slices.Contains(contents, s) {
+			redacted[i] = redactedContent
+		}
+	}
+
+	return redacted
+}
+
+func toSealed(content schema.EncryptedContent) (*envelope.Sealed, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(content.Content)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("decode ciphertext")
+	}
+
+	return &envelope.Sealed{
+		KeyID:      *content.KeyID,
+		DataKey:    content.DataKey,
+		Ciphertext: ciphertext,
+	}, nil
+}
+
+func fromSealed(sealed *envelope.Sealed) schema.EncryptedContent {
+	return schema.EncryptedContent{
+		Content: base64.StdEncoding.EncodeToString(sealed.Ciphertext),
+		DataKey: sealed.DataKey,
+		KeyID:   &sealed.KeyID,
+	}
+}
+
+// contentTable is a table keeping contents of notes
+type contentTable struct {
+	name string
+	// noteID is the column with the id of the note contents are bound to
+	noteID string
+	// rotated are assignments applied to rows along with rotated contents, if set
+	rotated string
+}
+
+// rotateKeys re-encrypts up to batchSize rows of the table not encrypted with the primary key,
+// returning the number of them. Rows are locked till the end of the transaction.
+func rotateKeys(ctx context.Context, db *poolwrapper.Pool, cipher contentCipher, table contentTable, batchSize int) (int, error) {
+	if cipher.keyring == nil {
+		return 0, apperrors.Internal(errors.New("encryption keys are not configured"))
+	}
+
+	query := `SELECT id, ` + table.noteID + ` AS note_id, content, data_key, key_id FROM ` + table.name + `
+		WHERE key_id IS DISTINCT FROM $1 ORDER BY id LIMIT $2 FOR UPDATE SKIP LOCKED`
+	args := []any{cipher.keyring.PrimaryID(), batchSize}
+
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return 0, apperrors.Internal(err).Wrap("pgx: query").
+			WithMetadata("query", query).
+			WithMetadata("args", args)
+	}
+
+	type encryptedRow struct {
+		ID     int `db:"id"`
+		NoteID int `db:"note_id"`
+		schema.EncryptedContent
+	}
+
+	encryptedRows, err := pgx.CollectRows(rows, pgx.RowToStructByName[encryptedRow])
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return 0, apperrors.Internal(err).Wrap("pgx.CollectRows")
+	}
+
+	update := `UPDATE ` + table.name + ` SET content = $2, data_key = $3, key_id = $4`
+	if table.rotated != "" {
+		update += `, ` + table.rotated
+	}
+	update += ` WHERE id = $1`
+
+	for _, row := range encryptedRows {
+		rotated, err := cipher.rotate(row.NoteID, row.EncryptedContent)
+		if err != nil {
+			return 0, fmt.Errorf("%s %d: %w", table.name, row.ID, err)
+		}
+
+		_, err = db.Exec(ctx, update, row.ID, rotated.Content, rotated.DataKey, rotated.KeyID)
+		if err != nil {
+			return 0, apperrors.Internal(err).Wrap("pgx: exec").
+				WithMetadata("query", update).
+				WithMetadata("id", row.ID)
+		}
+	}
+
+	return len(encryptedRows), nil
+}
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/apperrors"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/repository/postgres/schema"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/service/dto"
+	"github.com/illiafox/spy-cat-test-assignment/app/pkg/poolwrapper"
+	"github.com/jackc/pgx/v5"
+)
+
+type EventsRepository struct {
+	db *poolwrapper.Pool
+}
+
+func NewEventsRepository(db *poolwrapper.Pool) *EventsRepository {
+	return &EventsRepository{db: db}
+}
+
+func (r *EventsRepository) Create(ctx context.Context, params dto.CreateEventParams) error {
+	const query = `INSERT INTO mission_events(mission_id, type, actor, payload) VALUES ($1, $2, $3, $4)`
+
+	payload := params.Payload
+	if payload == nil {
+		payload = map[string]any{}
+	}
+
+	args := []any{params.MissionID, params.Type, params.Actor, payload}
+
+	_, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return apperrors.Internal(err).Wrap("create event: pgx: exec").
+			WithMetadata("query", query).
+			WithMetadata("args", args)
+	}
+
+	return nil
+}
+
+func (r *EventsRepository) All(ctx context.Context, missionID int) ([]*models.Event, error) {
+	var schemaEvents []schema.Event
+
+	const query = `SELECT id, mission_id, type, actor, payload, created_at
+		FROM mission_events WHERE mission_id = $1 ORDER BY created_at, id`
+
+	rows, err := r.db.Query(ctx, query, missionID)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("pgx: query").
+			WithMetadata("query", query).
+			WithMetadata("mission_id", missionID)
+	}
+
+	schemaEvents, err = pgx.CollectRows(rows, pgx.RowToStructByName[schema.Event])
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectRows")
+	}
+
+	events := make([]*models.Event, len(schemaEvents))
+	for i := range schemaEvents {
+		events[i] = schemaEvents[i].ToModel()
+	}
+
+	return events, nil
+}
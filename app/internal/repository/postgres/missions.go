@@ -14,6 +14,9 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
+const missionColumns = `id, assigned_cat_id, is_completed, budget, starts_at, ends_at, assigned_at, completed_at,
+	reopened_at, reopened_by, reopen_reason, created_at, updated_at`
+
 type MissionsRepository struct {
 	db *poolwrapper.Pool
 }
@@ -22,13 +25,15 @@ func NewMissionsRepository(db *poolwrapper.Pool) *MissionsRepository {
 	return &MissionsRepository{db: db}
 }
 
-func (r *MissionsRepository) Create(ctx context.Context) (missionID int, err error) {
-	query := "INSERT INTO missions DEFAULT VALUES RETURNING id"
+func (r *MissionsRepository) Create(ctx context.Context, params dto.CreateMissionParams) (missionID int, err error) {
+	query := "INSERT INTO missions(budget, starts_at, ends_at) VALUES ($1, $2, $3) RETURNING id"
+	args := []any{params.Budget, params.StartsAt, params.EndsAt}
 
-	err = r.db.QueryRow(ctx, query).Scan(&missionID)
+	err = r.db.QueryRow(ctx, query, args...).Scan(&missionID)
 	if err != nil {
 		return -1, apperrors.Internal(err).Wrap("create mission: pgx: query row").
-			WithMetadata("query", query)
+			WithMetadata("query", query).
+			WithMetadata("args", args)
 	}
 
 	return missionID, nil
@@ -52,15 +57,44 @@ func (r *MissionsRepository) Delete(ctx context.Context, missionID int) (err err
 }
 
 func (r *MissionsRepository) Update(ctx context.Context, params dto.UpdateMissionParams) (err error) {
+	now := time.Now()
+
 	builder := sqlbuilder.Update("missions")
-	builder.Set(builder.Assign("updated_at", time.Now()))
+	builder.Set(builder.Assign("updated_at", now))
 
 	if params.AssignedCatID != nil {
-		builder.SetMore(builder.Assign("assigned_cat_id", *params.AssignedCatID))
+		// assigning the same cat again keeps the assignment time, otherwise cost would be reset
+		builder.SetMore(
+			builder.Assign("assigned_cat_id", *params.AssignedCatID),
+			builder.Assign("assigned_at", sqlbuilder.Buildf(
+				"CASE WHEN assigned_cat_id IS DISTINCT FROM %v THEN %v ELSE assigned_at END",
+				*params.AssignedCatID, now,
+			)),
+		)
+	} else if params.Unassign {
+		builder.SetMore(
+			builder.Assign("assigned_cat_id", nil),
+			builder.Assign("assigned_at", nil),
+		)
 	}
 
 	if params.IsCompleted != nil {
 		builder.SetMore(builder.Assign("is_completed", *params.IsCompleted))
+		if *params.IsCompleted {
+			builder.SetMore(builder.Assign("completed_at", now))
+		}
+	}
+
+	if params.Budget != nil {
+		builder.SetMore(builder.Assign("budget", *params.Budget))
+	}
+
+	if params.StartsAt != nil {
+		builder.SetMore(builder.Assign("starts_at", *params.StartsAt))
+	}
+
+	if params.EndsAt != nil {
+		builder.SetMore(builder.Assign("ends_at", *params.EndsAt))
 	}
 
 	query, args := builder.Where(builder.Equal("id", params.MissionID)).
@@ -82,11 +116,65 @@ func (r *MissionsRepository) Update(ctx context.Context, params dto.UpdateMissio
 	return nil
 }
 
+func (r *MissionsRepository) Reopen(ctx context.Context, params dto.ReopenParams) (err error) {
+	const query = `UPDATE missions SET is_completed = false, completed_at = NULL,
+		reopened_at = NOW(), reopened_by = $2, reopen_reason = $3, updated_at = NOW()
+		WHERE id = $1`
+	args := []any{params.MissionID, params.ReopenedBy, params.Reason}
+
+	res, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return apperrors.Internal(err).Wrap("pgx: exec").
+			WithMetadata("query", query).
+			WithMetadata("args", args)
+	}
+
+	if res.RowsAffected() == 0 {
+		return apperrors.MissionNotFound(params.MissionID)
+	}
+
+	return nil
+}
+
+// UnassignCat unassigns the cat from all of its missions
+func (r *MissionsRepository) UnassignCat(ctx context.Context, catID int) error {
+	const query = `UPDATE missions SET assigned_cat_id = NULL, assigned_at = NULL, updated_at = NOW()
+		WHERE assigned_cat_id = $1`
+
+	_, err := r.db.Exec(ctx, query, catID)
+	if err != nil {
+		return apperrors.Internal(err).Wrap("pgx: exec").
+			WithMetadata("query", query).
+			WithMetadata("cat_id", catID)
+	}
+
+	return nil
+}
+
+// Lock locks the mission row until the end of the transaction
+func (r *MissionsRepository) Lock(ctx context.Context, missionID int) error {
+	const query = `SELECT id FROM missions WHERE id = $1 FOR UPDATE`
+
+	var id int
+
+	err := r.db.QueryRow(ctx, query, missionID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return apperrors.MissionNotFound(missionID)
+		}
+
+		return apperrors.Internal(err).Wrap("pgx: query row").
+			WithMetadata("query", query).
+			WithMetadata("mission_id", missionID)
+	}
+
+	return nil
+}
+
 func (r *MissionsRepository) One(ctx context.Context, missionID int) (*models.Mission, error) {
 	var mission schema.Mission
 
-	const query = `SELECT id, assigned_cat_id, is_completed, created_at, updated_at
-		FROM missions WHERE id = $1`
+	const query = `SELECT ` + missionColumns + ` FROM missions WHERE id = $1`
 
 	rows, err := r.db.Query(ctx, query, missionID)
 	if err != nil {
@@ -107,16 +195,44 @@ func (r *MissionsRepository) One(ctx context.Context, missionID int) (*models.Mi
 	return mission.ToModel(), nil
 }
 
+func (r *MissionsRepository) ByIDs(ctx context.Context, missionIDs []int) ([]*models.Mission, error) {
+	var schemaMissions []schema.Mission
+
+	const query = `SELECT ` + missionColumns + ` FROM missions WHERE id = ANY($1)`
+
+	rows, err := r.db.Query(ctx, query, missionIDs)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("pgx: query").
+			WithMetadata("query", query).
+			WithMetadata("mission_ids", missionIDs)
+	}
+
+	schemaMissions, err = pgx.CollectRows(rows, pgx.RowToStructByName[schema.Mission])
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectRows")
+	}
+
+	missions := make([]*models.Mission, len(schemaMissions))
+	for i := range schemaMissions {
+		missions[i] = schemaMissions[i].ToModel()
+	}
+
+	return missions, nil
+}
+
 func (r *MissionsRepository) All(ctx context.Context, params dto.GetMissionsParams) ([]*models.Mission, error) {
 	var schemaMissions []schema.Mission
 
-	builder := sqlbuilder.Select("id", "assigned_cat_id", "is_completed", "created_at", "updated_at").
-		From("missions")
+	builder := sqlbuilder.Select(missionColumns).From("missions")
 
 	if params.CatID != nil {
 		builder.Where(builder.Equal("assigned_cat_id", *params.CatID))
 	}
 
+	if params.IsCompleted != nil {
+		builder.Where(builder.Equal("is_completed", *params.IsCompleted))
+	}
+
 	query, args := builder.Build()
 
 	rows, err := r.db.Query(ctx, query, args...)
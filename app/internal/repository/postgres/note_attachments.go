@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/apperrors"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/repository/postgres/schema"
+	"github.com/illiafox/spy-cat-test-assignment/app/pkg/poolwrapper"
+	"github.com/jackc/pgx/v5"
+)
+
+const noteAttachmentColumns = `id, note_id, mission_id, target_id, file_name, content_type, size, storage_key, created_at`
+
+type NoteAttachmentsRepository struct {
+	db *poolwrapper.Pool
+}
+
+func NewNoteAttachmentsRepository(db *poolwrapper.Pool) *NoteAttachmentsRepository {
+	return &NoteAttachmentsRepository{db: db}
+}
+
+func (r *NoteAttachmentsRepository) Create(ctx context.Context, attachment *models.NoteAttachment) (attachmentID int, err error) {
+	const query = `INSERT INTO note_attachments(note_id, mission_id, target_id, file_name, content_type, size, storage_key)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	args := []any{
+		attachment.NoteID, attachment.MissionID, attachment.TargetID,
+		attachment.FileName, attachment.ContentType, attachment.Size, attachment.StorageKey,
+	}
+
+	err = r.db.QueryRow(ctx, query, args...).Scan(&attachmentID)
+	if err != nil {
+		return -1, apperrors.Internal(err).Wrap("create attachment: pgx: query row").
+			WithMetadata("query", query).
+			WithMetadata("args", args)
+	}
+
+	return attachmentID, nil
+}
+
+func (r *NoteAttachmentsRepository) One(ctx context.Context, noteID, attachmentID int) (*models.NoteAttachment, error) {
+	var attachment schema.NoteAttachment
+
+	const query = `SELECT ` + noteAttachmentColumns + ` FROM note_attachments WHERE note_id = $1 AND id = $2`
+	args := []any{noteID, attachmentID}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("pgx: query attachment").
+			WithMetadata("query", query).
+			WithMetadata("args", args)
+	}
+
+	attachment, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[schema.NoteAttachment])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.NoteAttachmentNotFound(attachmentID)
+		}
+
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectOneRow")
+	}
+
+	return attachment.ToModel(), nil
+}
+
+func (r *NoteAttachmentsRepository) All(ctx context.Context, noteID int) ([]*models.NoteAttachment, error) {
+	const query = `SELECT ` + noteAttachmentColumns + ` FROM note_attachments WHERE note_id = $1 ORDER BY created_at, id`
+
+	return r.all(ctx, query, noteID)
+}
+
+func (r *NoteAttachmentsRepository) AllByTarget(ctx context.Context, missionID, targetID int) ([]*models.NoteAttachment, error) {
+	const query = `SELECT ` + noteAttachmentColumns + ` FROM note_attachments
+		WHERE mission_id = $1 AND target_id = $2 ORDER BY created_at, id`
+
+	return r.all(ctx, query, missionID, targetID)
+}
+
+func (r *NoteAttachmentsRepository) AllByMission(ctx context.Context, missionID int) ([]*models.NoteAttachment, error) {
+	const query = `SELECT ` + noteAttachmentColumns + ` FROM note_attachments WHERE mission_id = $1 ORDER BY created_at, id`
+
+	return r.all(ctx, query, missionID)
+}
+
+func (r *NoteAttachmentsRepository) all(ctx context.Context, query string, args ...any) ([]*models.NoteAttachment, error) {
+	var schemaAttachments []schema.NoteAttachment
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("pgx: query").
+			WithMetadata("query", query).
+			WithMetadata("args", args)
+	}
+
+	schemaAttachments, err = pgx.CollectRows(rows, pgx.RowToStructByName[schema.NoteAttachment])
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectRows")
+	}
+
+	attachments := make([]*models.NoteAttachment, len(schemaAttachments))
+	for i := range schemaAttachments {
+		attachments[i] = schemaAttachments[i].ToModel()
+	}
+
+	return attachments, nil
+}
+
+func (r *NoteAttachmentsRepository) Delete(ctx context.Context, noteID, attachmentID int) error {
+	const query = `DELETE FROM note_attachments WHERE note_id = $1 AND id = $2`
+	args := []any{noteID, attachmentID}
+
+	res, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return apperrors.Internal(err).Wrap("pgx: exec").
+			WithMetadata("query", query).
+			WithMetadata("args", args)
+	}
+
+	if res.RowsAffected() == 0 {
+		return apperrors.NoteAttachmentNotFound(attachmentID)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/apperrors"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/repository/postgres/schema"
+	"github.com/illiafox/spy-cat-test-assignment/app/pkg/envelope"
+	"github.com/illiafox/spy-cat-test-assignment/app/pkg/poolwrapper"
+	"github.com/jackc/pgx/v5"
+)
+
+// NoteRevisionsRepository keeps contents encrypted the same way as the notes they are copied from,
+// they stay bound to the note
+type NoteRevisionsRepository struct {
+	db     *poolwrapper.Pool
+	cipher contentCipher
+}
+
+func NewNoteRevisionsRepository(db *poolwrapper.Pool, keyring *envelope.Keyring) *NoteRevisionsRepository {
+	return &NoteRevisionsRepository{db: db, cipher: contentCipher{keyring: keyring}}
+}
+
+// Create stores the current content of the notes as their next revisions
+func (r *NoteRevisionsRepository) Create(ctx context.Context, noteIDs []int, editor string) error {
+	const query = `INSERT INTO note_revisions(note_id, mission_id, target_id, revision, content, data_key, key_id, editor)
+		SELECT n.id, n.mission_id, n.target_id,
+			COALESCE((SELECT MAX(r.revision) FROM note_revisions r WHERE r.note_id = n.id), 0) + 1,
+			n.content, n.data_key, n.key_id, $2
+		FROM notes n WHERE n.id = ANY($1)`
+	args := []any{noteIDs, editor}
+
+	_, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return apperrors.Internal(err).Wrap("create note revisions: pgx: exec").
+			WithMetadata("query", query).
+			WithMetadata("args", args)
+	}
+
+	return nil
+}
+
+func (r *NoteRevisionsRepository) All(ctx context.Context, missionID, targetID, noteID int) ([]*models.NoteRevision, error) {
+	var schemaRevisions []schema.NoteRevision
+
+	const query = `SELECT id, note_id, mission_id, target_id, revision, content, data_key, key_id, editor, created_at
+		FROM note_revisions WHERE mission_id = $1 AND target_id = $2 AND note_id = $3 ORDER BY revision`
+	args := []any{missionID, targetID, noteID}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("pgx: query").
+			WithMetadata("query", query).
+			WithMetadata("args", args)
+	}
+
+	schemaRevisions, err = pgx.CollectRows(rows, pgx.RowToStructByName[schema.NoteRevision])
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectRows")
+	}
+
+	revisions := make([]*models.NoteRevision, len(schemaRevisions))
+	for i := range schemaRevisions {
+		err = r.cipher.decrypt(schemaRevisions[i].NoteID, &schemaRevisions[i].EncryptedContent)
+		if err != nil {
+			return nil, fmt.Errorf("note revision %d: %w", schemaRevisions[i].ID, err)
+		}
+
+		revisions[i] = schemaRevisions[i].ToModel()
+	}
+
+	return revisions, nil
+}
+
+// RotateKeys encrypts up to batchSize revisions not encrypted with the primary key yet, returning the number of them.
+// Must be called within a transaction, rows are locked till it ends.
+func (r *NoteRevisionsRepository) RotateKeys(ctx context.Context, batchSize int) (int, error) {
+	return rotateKeys(ctx, r.db, r.cipher, contentTable{name: "note_revisions", noteID: "note_id"}, batchSize)
+}
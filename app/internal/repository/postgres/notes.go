@@ -3,51 +3,202 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 
 	"github.com/huandu/go-sqlbuilder"
 	"github.com/illiafox/spy-cat-test-assignment/app/internal/apperrors"
 	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
 	"github.com/illiafox/spy-cat-test-assignment/app/internal/repository/postgres/schema"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/service/dto"
+	"github.com/illiafox/spy-cat-test-assignment/app/pkg/envelope"
 	"github.com/illiafox/spy-cat-test-assignment/app/pkg/poolwrapper"
 	"github.com/jackc/pgx/v5"
 )
 
+const noteColumns = `id, mission_id, target_id, author_cat_id, content, data_key, key_id, created_at, updated_at`
+
+// NotesRepository encrypts contents of notes if the keyring is set, and decrypts them transparently.
+// Encrypted notes are searched only if searchEncrypted is set, see Search.
 type NotesRepository struct {
-	db *poolwrapper.Pool
+	db              *poolwrapper.Pool
+	cipher          contentCipher
+	searchEncrypted bool
+}
+
+func NewNotesRepository(db *poolwrapper.Pool, keyring *envelope.Keyring, searchEncrypted bool) *NotesRepository {
+	return &NotesRepository{db: db, cipher: contentCipher{keyring: keyring}, searchEncrypted: searchEncrypted}
 }
 
-func NewNotesRepository(db *poolwrapper.Pool) *NotesRepository {
-	return &NotesRepository{db: db}
+func (r *NotesRepository) Create(ctx context.Context, params dto.CreateNotesParams) (noteIDs []int, err error) {
+	// contents are bound to ids of their notes, so ids are taken before the notes are encrypted
+	noteIDs, err = r.nextIDs(ctx, len(params.Contents))
+	if err != nil {
+		return nil, err
+	}
+
+	builder := sqlbuilder.InsertInto("notes").Cols(
+		"id", "mission_id", "target_id", "author_cat_id",
+		"content", "data_key", "key_id", "search_vector",
+	)
+
+	contents := make([]string, 0, 2*len(params.Contents))
+	for i, content := range params.Contents {
+		encrypted, err := r.cipher.encrypt(noteIDs[i], content)
+		if err != nil {
+			return nil, err
+		}
+
+		contents = append(contents, content, encrypted.Content)
+
+		builder.Values(
+			noteIDs[i], params.MissionID, params.TargetID, params.AuthorCatID,
+			encrypted.Content, encrypted.DataKey, encrypted.KeyID,
+			r.searchVector(content),
+		)
+	}
+
+	query, args := builder.Build()
+
+	_, err = r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("create notes: pgx: exec").
+			WithMetadata("query", query).
+			WithMetadata("args", redactContents(args, contents...))
+	}
+
+	return noteIDs, nil
+}
+
+// nextIDs takes count ids of new notes from the sequence, in ascending order
+func (r *NotesRepository) nextIDs(ctx context.Context, count int) ([]int, error) {
+	const query = `SELECT NEXTVAL(PG_GET_SERIAL_SEQUENCE('notes', 'id'))::INTEGER FROM GENERATE_SERIES(1, $1)`
+	args := []any{count}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("pgx: query note ids").
+			WithMetadata("query", query).
+			WithMetadata("args", args)
+	}
+
+	noteIDs, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectRows")
+	}
+
+	slices.Sort(noteIDs)
+
+	return noteIDs, nil
 }
 
-func (r *NotesRepository) Create(ctx context.Context, missionID, targetID int, contents []string) error {
-	builder := sqlbuilder.InsertInto("notes").Cols("mission_id", "target_id", "content")
-	for _, content := range contents {
-		builder.Values(missionID, targetID, content)
+func (r *NotesRepository) Update(ctx context.Context, params dto.UpdateNoteParams) error {
+	encrypted, err := r.cipher.encrypt(params.NoteID, params.Content)
+	if err != nil {
+		return err
 	}
 
+	builder := sqlbuilder.Update("notes")
+	builder.Set(
+		builder.Assign("content", encrypted.Content),
+		builder.Assign("data_key", encrypted.DataKey),
+		builder.Assign("key_id", encrypted.KeyID),
+		builder.Assign("search_vector", r.searchVector(params.Content)),
+		"updated_at = NOW()",
+	).Where(
+		builder.Equal("mission_id", params.MissionID),
+		builder.Equal("target_id", params.TargetID),
+		builder.Equal("id", params.NoteID),
+	)
+
 	query, args := builder.Build()
 
-	_, err := r.db.Exec(ctx, query, args...)
+	res, err := r.db.Exec(ctx, query, args...)
 	if err != nil {
-		return apperrors.Internal(err).Wrap("create notes: pgx: exec").
+		return apperrors.Internal(err).Wrap("pgx: exec").
+			WithMetadata("query", query).
+			WithMetadata("args", redactContents(args, params.Content, encrypted.Content))
+	}
+
+	if res.RowsAffected() == 0 {
+		return apperrors.NoteNotFound(params.NoteID)
+	}
+
+	return nil
+}
+
+func (r *NotesRepository) Delete(ctx context.Context, missionID, targetID, noteID int) error {
+	const query = `DELETE FROM notes WHERE mission_id = $1 AND target_id = $2 AND id = $3`
+	args := []any{missionID, targetID, noteID}
+
+	res, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return apperrors.Internal(err).Wrap("pgx: exec").
 			WithMetadata("query", query).
 			WithMetadata("args", args)
 	}
 
+	if res.RowsAffected() == 0 {
+		return apperrors.NoteNotFound(noteID)
+	}
+
 	return nil
 }
 
-func (r *NotesRepository) All(ctx context.Context, missionID, targetID int) ([]*models.Note, error) {
+func (r *NotesRepository) One(ctx context.Context, missionID, targetID, noteID int) (*models.Note, error) {
+	var note schema.Note
+
+	const query = `SELECT ` + noteColumns + ` FROM notes WHERE mission_id = $1 AND target_id = $2 AND id = $3`
+	args := []any{missionID, targetID, noteID}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("pgx: query note").
+			WithMetadata("query", query).
+			WithMetadata("args", args)
+	}
+
+	note, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[schema.Note])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.NoteNotFound(noteID)
+		}
+
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectOneRow")
+	}
+
+	err = r.cipher.decrypt(note.ID, &note.EncryptedContent)
+	if err != nil {
+		return nil, fmt.Errorf("note %d: %w", noteID, err)
+	}
+
+	return note.ToModel(), nil
+}
+
+// All returns target's notes, the newest first. Only notes older than before are returned if it's set.
+// Zero limit means unlimited.
+func (r *NotesRepository) All(ctx context.Context, missionID, targetID int, filter dto.NotesFilter, before *models.NotesCursor, limit int) ([]*models.Note, error) {
 	var schemaNotes []schema.Note
 
-	builder := sqlbuilder.Select("mission_id", "target_id", "content", "created_at").
-		From("notes").OrderBy("created_at").Desc()
+	builder := sqlbuilder.Select(noteColumns).
+		From("notes").OrderBy("created_at DESC", "id DESC")
 
-	query, args := builder.Where(
+	builder.Where(
 		builder.Equal("mission_id", missionID),
 		builder.Equal("target_id", targetID),
-	).Build()
+	)
+	applyNotesFilter(builder, filter)
+
+	// keyset pagination, notes created at the same time are ordered by id
+	if before != nil {
+		builder.Where(fmt.Sprintf("(created_at, id) < (%s, %s)", builder.Var(before.CreatedAt), builder.Var(before.ID)))
+	}
+
+	if limit > 0 {
+		builder.Limit(limit)
+	}
+
+	query, args := builder.Build()
 
 	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
@@ -61,10 +212,323 @@ func (r *NotesRepository) All(ctx context.Context, missionID, targetID int) ([]*
 		return nil, apperrors.Internal(err).Wrap("pgx.CollectRows")
 	}
 
+	return r.toModels(schemaNotes)
+}
+
+// AllByMissions returns notes of all targets of the given missions with a single query, the newest first.
+// Only limitPerTarget latest notes of each target are returned, zero means unlimited.
+func (r *NotesRepository) AllByMissions(ctx context.Context, missionIDs []int, filter dto.NotesFilter, limitPerTarget int) ([]*models.Note, error) {
+	var schemaNotes []schema.Note
+
+	notes := sqlbuilder.Select(noteColumns).From("notes")
+	notes.Where(notes.Any("mission_id", "=", missionIDs))
+	applyNotesFilter(notes, filter)
+
+	builder := notes
+	if limitPerTarget > 0 {
+		notes.Select(noteColumns,
+			"ROW_NUMBER() OVER (PARTITION BY mission_id, target_id ORDER BY created_at DESC, id DESC) AS position",
+		)
+
+		builder = sqlbuilder.Select(noteColumns)
+		builder.From(builder.BuilderAs(notes, "latest")).
+			Where(builder.LessEqualThan("position", limitPerTarget))
+	}
+
+	query, args := builder.OrderBy("created_at DESC", "id DESC").Build()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("pgx: query").
+			WithMetadata("query", query).
+			WithMetadata("args", args)
+	}
+
+	schemaNotes, err = pgx.CollectRows(rows, pgx.RowToStructByName[schema.Note])
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectRows")
+	}
+
+	return r.toModels(schemaNotes)
+}
+
+// CountByMissions counts notes of each target of the given missions, targets without notes are omitted
+func (r *NotesRepository) CountByMissions(ctx context.Context, missionIDs []int, filter dto.NotesFilter) ([]*models.NotesCount, error) {
+	var schemaCounts []schema.NotesCount
+
+	builder := sqlbuilder.Select("mission_id", "target_id", "COUNT(*) AS count").
+		From("notes").GroupBy("mission_id", "target_id")
+
+	builder.Where(builder.Any("mission_id", "=", missionIDs))
+	applyNotesFilter(builder, filter)
+
+	query, args := builder.Build()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("pgx: query").
+			WithMetadata("query", query).
+			WithMetadata("args", args)
+	}
+
+	schemaCounts, err = pgx.CollectRows(rows, pgx.RowToStructByName[schema.NotesCount])
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectRows")
+	}
+
+	counts := make([]*models.NotesCount, len(schemaCounts))
+	for i := range schemaCounts {
+		counts[i] = schemaCounts[i].ToModel()
+	}
+
+	return counts, nil
+}
+
+// searchColumns are columns of schema.NoteSearchResult, except for the rank
+var searchColumns = []string{
+	"n.id", "n.mission_id", "n.target_id", "n.author_cat_id", "n.content", "n.data_key", "n.key_id",
+	"n.created_at", "n.updated_at",
+	"t.name AS target_name", "t.country",
+}
+
+// Search returns notes matching the query, the most relevant first.
+// Encrypted notes are not indexed, they are decrypted and matched on every search if searchEncrypted is set.
+func (r *NotesRepository) Search(ctx context.Context, params dto.SearchNotesParams) ([]*models.NoteSearchResult, error) {
+	tsQuery := toTSQuery(params.Query)
+	if tsQuery == "" {
+		return nil, apperrors.InvalidRequest(errors.New("search query has no words to search for"))
+	}
+
+	if r.cipher.keyring == nil {
+		return r.searchIndexed(ctx, params, tsQuery)
+	}
+
+	if !r.searchEncrypted {
+		return nil, apperrors.SearchUnavailable("contents of notes are encrypted, and searching them is disabled")
+	}
+
+	return r.searchDecrypted(ctx, params, tsQuery)
+}
+
+// searchIndexed matches plain text notes by the search index
+func (r *NotesRepository) searchIndexed(ctx context.Context, params dto.SearchNotesParams, tsQuery string) ([]*models.NoteSearchResult, error) {
+	builder := sqlbuilder.NewSelectBuilder()
+	builder.Select(append(searchColumns, "TS_RANK(n.search_vector, q.query) AS rank")...).From(
+		"TO_TSQUERY('"+searchConfig+"', "+builder.Var(tsQuery)+") AS q(query)",
+		"notes n",
+	).Join("targets t", "t.mission_id = n.mission_id", "t.id = n.target_id")
+
+	builder.Where("n.search_vector @@ q.query")
+	applySearchFilters(builder, params)
+
+	builder.OrderBy("rank DESC", "n.created_at DESC", "n.id DESC").Limit(params.Limit)
+
+	schemaResults, err := r.searchResults(ctx, builder)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := make([]string, len(schemaResults))
+	for i := range schemaResults {
+		contents[i] = schemaResults[i].Content
+	}
+
+	snippets, err := r.headlines(ctx, contents, tsQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*models.NoteSearchResult, len(schemaResults))
+	for i := range schemaResults {
+		schemaResults[i].Snippet = snippets[i]
+		results[i] = schemaResults[i].ToModel()
+	}
+
+	return results, nil
+}
+
+// searchDecrypted decrypts every note matching the filters and matches them by search vectors made on the fly,
+// which are never stored. It takes time proportional to the number of notes, and decrypted contents are sent
+// to the database to be matched.
+func (r *NotesRepository) searchDecrypted(ctx context.Context, params dto.SearchNotesParams, tsQuery string) ([]*models.NoteSearchResult, error) {
+	builder := sqlbuilder.NewSelectBuilder()
+	builder.Select(append(searchColumns, "0::FLOAT8 AS rank")...).
+		From("notes n").
+		Join("targets t", "t.mission_id = n.mission_id", "t.id = n.target_id")
+
+	applySearchFilters(builder, params)
+
+	// positions of candidates break ties of ranks, the same way as created_at and id do for indexed notes
+	builder.OrderBy("n.created_at DESC", "n.id DESC")
+
+	candidates, err := r.searchResults(ctx, builder)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(candidates) == 0 {
+		return []*models.NoteSearchResult{}, nil
+	}
+
+	contents := make([]string, len(candidates))
+	escaped := make([]string, len(candidates))
+	for i := range candidates {
+		contents[i] = candidates[i].Content
+		escaped[i] = snippetEscaper.Replace(candidates[i].Content)
+	}
+
+	const query = `SELECT c.position, TS_RANK(v.vector, q.query) AS rank,
+			TS_HEADLINE('` + searchConfig + `', c.escaped, q.query, '` + searchHeadlineOptions + `') AS snippet
+		FROM UNNEST($1::TEXT[], $2::TEXT[]) WITH ORDINALITY AS c(content, escaped, position),
+			TO_TSQUERY('` + searchConfig + `', $3) AS q(query),
+			LATERAL TO_TSVECTOR('` + searchConfig + `', c.content) AS v(vector)
+		WHERE v.vector @@ q.query
+		ORDER BY rank DESC, c.position
+		LIMIT $4`
+	args := []any{contents, escaped, tsQuery, params.Limit}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		// args are left out, they hold contents of notes
+		return nil, apperrors.Internal(err).Wrap("pgx: query matches").
+			WithMetadata("query", query)
+	}
+
+	type match struct {
+		Position int     `db:"position"`
+		Rank     float64 `db:"rank"`
+		Snippet  string  `db:"snippet"`
+	}
+
+	matches, err := pgx.CollectRows(rows, pgx.RowToStructByName[match])
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectRows")
+	}
+
+	results := make([]*models.NoteSearchResult, len(matches))
+	for i, m := range matches {
+		// positions are 1-based
+		result := candidates[m.Position-1]
+		result.Rank = m.Rank
+		result.Snippet = m.Snippet
+		results[i] = result.ToModel()
+	}
+
+	return results, nil
+}
+
+// searchResults queries notes for search, decrypting their contents
+func (r *NotesRepository) searchResults(ctx context.Context, builder *sqlbuilder.SelectBuilder) ([]schema.NoteSearchResult, error) {
+	query, args := builder.Build()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("pgx: query").
+			WithMetadata("query", query).
+			WithMetadata("args", args)
+	}
+
+	schemaResults, err := pgx.CollectRows(rows, pgx.RowToStructByName[schema.NoteSearchResult])
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectRows")
+	}
+
+	for i := range schemaResults {
+		err = r.cipher.decrypt(schemaResults[i].ID, &schemaResults[i].EncryptedContent)
+		if err != nil {
+			return nil, fmt.Errorf("note %d: %w", schemaResults[i].ID, err)
+		}
+	}
+
+	return schemaResults, nil
+}
+
+func applySearchFilters(builder *sqlbuilder.SelectBuilder, params dto.SearchNotesParams) {
+	if params.MissionID != nil {
+		builder.Where(builder.Equal("n.mission_id", *params.MissionID))
+	}
+
+	if params.TargetID != nil {
+		builder.Where(builder.Equal("n.target_id", *params.TargetID))
+	}
+
+	if params.Country != nil {
+		builder.Where(builder.Equal("t.country", *params.Country))
+	}
+
+	if params.From != nil {
+		builder.Where(builder.GreaterEqualThan("n.created_at", *params.From))
+	}
+
+	if params.To != nil {
+		builder.Where(builder.LessThan("n.created_at", *params.To))
+	}
+}
+
+// searchVector is the value of the search index of the content.
+// Contents are not indexed when they are encrypted, the index would keep their words in plain text.
+func (r *NotesRepository) searchVector(content string) any {
+	if r.cipher.keyring != nil {
+		return nil
+	}
+
+	return sqlbuilder.Buildf("TO_TSVECTOR('"+searchConfig+"', %v)", content)
+}
+
+// headlines marks matches of the query in HTML-escaped contents
+func (r *NotesRepository) headlines(ctx context.Context, contents []string, tsQuery string) ([]string, error) {
+	if len(contents) == 0 {
+		return []string{}, nil
+	}
+
+	escaped := make([]string, len(contents))
+	for i, content := range contents {
+		escaped[i] = snippetEscaper.Replace(content)
+	}
+
+	const query = `SELECT TS_HEADLINE('` + searchConfig + `', c.content, TO_TSQUERY('` + searchConfig + `', $2),
+		'` + searchHeadlineOptions + `')
+		FROM UNNEST($1::TEXT[]) WITH ORDINALITY AS c(content, position) ORDER BY c.position`
+	args := []any{escaped, tsQuery}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		// args are left out, they hold contents of notes
+		return nil, apperrors.Internal(err).Wrap("pgx: query headlines").
+			WithMetadata("query", query)
+	}
+
+	headlines, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectRows")
+	}
+
+	return headlines, nil
+}
+
+// RotateKeys encrypts up to batchSize notes not encrypted with the primary key yet, returning the number of them.
+// Must be called within a transaction, rows are locked till it ends.
+func (r *NotesRepository) RotateKeys(ctx context.Context, batchSize int) (int, error) {
+	// search vectors of notes encrypted for the first time are dropped, see searchVector
+	return rotateKeys(ctx, r.db, r.cipher, contentTable{name: "notes", noteID: "id", rotated: "search_vector = NULL"}, batchSize)
+}
+
+func (r *NotesRepository) toModels(schemaNotes []schema.Note) ([]*models.Note, error) {
 	notes := make([]*models.Note, len(schemaNotes))
 	for i := range schemaNotes {
+		err := r.cipher.decrypt(schemaNotes[i].ID, &schemaNotes[i].EncryptedContent)
+		if err != nil {
+			return nil, fmt.Errorf("note %d: %w", schemaNotes[i].ID, err)
+		}
+
 		notes[i] = schemaNotes[i].ToModel()
 	}
 
 	return notes, nil
 }
+
+func applyNotesFilter(builder *sqlbuilder.SelectBuilder, filter dto.NotesFilter) {
+	if filter.AuthorCatID != nil {
+		builder.Where(builder.Equal("author_cat_id", *filter.AuthorCatID))
+	}
+}
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/apperrors"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/repository/postgres/schema"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/service/dto"
+	"github.com/illiafox/spy-cat-test-assignment/app/pkg/poolwrapper"
+	"github.com/jackc/pgx/v5"
+)
+
+type PersonsRepository struct {
+	db *poolwrapper.Pool
+}
+
+func NewPersonsRepository(db *poolwrapper.Pool) *PersonsRepository {
+	return &PersonsRepository{db: db}
+}
+
+func (r *PersonsRepository) Create(ctx context.Context, params dto.CreatePersonParams) (personID int, err error) {
+	const query = `INSERT INTO persons(name, country) VALUES ($1, $2) RETURNING id`
+	args := []any{params.Name, params.Country}
+
+	err = r.db.QueryRow(ctx, query, args...).Scan(&personID)
+	if err != nil {
+		return -1, apperrors.Internal(err).Wrap("pgx: query row").
+			WithMetadata("query", query).
+			WithMetadata("args", args)
+	}
+
+	return personID, nil
+}
+
+func (r *PersonsRepository) One(ctx context.Context, personID int) (*models.Person, error) {
+	var person schema.Person
+
+	const query = `SELECT id, name, country, created_at, updated_at FROM persons WHERE id = $1`
+
+	rows, err := r.db.Query(ctx, query, personID)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("pgx: query person").
+			WithMetadata("query", query).
+			WithMetadata("person_id", personID)
+	}
+
+	person, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[schema.Person])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.PersonNotFound(personID)
+		}
+
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectOneRow")
+	}
+
+	return person.ToModel(), nil
+}
@@ -22,11 +22,19 @@ func (c Cat) ToModel() *models.Cat {
 }
 
 type Mission struct {
-	ID            int       `db:"id"`
-	AssignedCatID *int      `db:"assigned_cat_id"`
-	IsCompleted   bool      `db:"is_completed"`
-	CreatedAt     time.Time `db:"created_at"`
-	UpdatedAt     time.Time `db:"updated_at"`
+	ID            int        `db:"id"`
+	AssignedCatID *int       `db:"assigned_cat_id"`
+	IsCompleted   bool       `db:"is_completed"`
+	Budget        *int       `db:"budget"`
+	StartsAt      *time.Time `db:"starts_at"`
+	EndsAt        *time.Time `db:"ends_at"`
+	AssignedAt    *time.Time `db:"assigned_at"`
+	CompletedAt   *time.Time `db:"completed_at"`
+	ReopenedAt    *time.Time `db:"reopened_at"`
+	ReopenedBy    *string    `db:"reopened_by"`
+	ReopenReason  *string    `db:"reopen_reason"`
+	CreatedAt     time.Time  `db:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at"`
 }
 
 func (m Mission) ToModel() *models.Mission {
@@ -35,28 +43,238 @@ func (m Mission) ToModel() *models.Mission {
 }
 
 type Target struct {
-	ID          int       `db:"id"`
-	MissionID   int       `db:"mission_id"`
-	IsCompleted bool      `db:"is_completed"`
-	Name        string    `db:"name"`
-	Country     string    `db:"country"`
-	CreatedAt   time.Time `db:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at"`
+	ID           int        `db:"id"`
+	MissionID    int        `db:"mission_id"`
+	IsCompleted  bool       `db:"is_completed"`
+	Name         string     `db:"name"`
+	Country      string     `db:"country"`
+	City         *string    `db:"city"`
+	PersonID     *int       `db:"person_id"`
+	RiskLevel    string     `db:"risk_level"`
+	Priority     int        `db:"priority"`
+	Latitude     *float64   `db:"latitude"`
+	Longitude    *float64   `db:"longitude"`
+	ReopenedAt   *time.Time `db:"reopened_at"`
+	ReopenedBy   *string    `db:"reopened_by"`
+	ReopenReason *string    `db:"reopen_reason"`
+	CreatedAt    time.Time  `db:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at"`
 }
 
 func (t Target) ToModel() *models.Target {
-	target := models.Target(t)
-	return &target
+	return &models.Target{
+		ID:           t.ID,
+		MissionID:    t.MissionID,
+		IsCompleted:  t.IsCompleted,
+		Name:         t.Name,
+		Country:      t.Country,
+		City:         t.City,
+		PersonID:     t.PersonID,
+		RiskLevel:    models.RiskLevel(t.RiskLevel),
+		Priority:     t.Priority,
+		Latitude:     t.Latitude,
+		Longitude:    t.Longitude,
+		ReopenedAt:   t.ReopenedAt,
+		ReopenedBy:   t.ReopenedBy,
+		ReopenReason: t.ReopenReason,
+		CreatedAt:    t.CreatedAt,
+		UpdatedAt:    t.UpdatedAt,
+	}
+}
+
+type NearbyTarget struct {
+	Target
+	DistanceKm float64 `db:"distance_km"`
+}
+
+func (t NearbyTarget) ToModel() *models.NearbyTarget {
+	return &models.NearbyTarget{
+		Target:     t.Target.ToModel(),
+		DistanceKm: t.DistanceKm,
+	}
 }
 
+// EncryptedContent is the content of a note as stored, it's encrypted unless KeyID is nil
+type EncryptedContent struct {
+	Content string  `db:"content"`
+	DataKey []byte  `db:"data_key"`
+	KeyID   *string `db:"key_id"`
+}
+
+// Note content must be decrypted before converting to model
 type Note struct {
-	ID        int       `db:"mission_id"`
-	TargetID  int       `db:"target_id"`
-	Content   string    `db:"content"`
+	ID          int  `db:"id"`
+	MissionID   int  `db:"mission_id"`
+	TargetID    int  `db:"target_id"`
+	AuthorCatID *int `db:"author_cat_id"`
+	EncryptedContent
 	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
 }
 
 func (n Note) ToModel() *models.Note {
-	note := models.Note(n)
-	return &note
+	return &models.Note{
+		ID:          n.ID,
+		MissionID:   n.MissionID,
+		TargetID:    n.TargetID,
+		AuthorCatID: n.AuthorCatID,
+		Content:     n.Content,
+		CreatedAt:   n.CreatedAt,
+		UpdatedAt:   n.UpdatedAt,
+	}
+}
+
+type NotesCount struct {
+	MissionID int `db:"mission_id"`
+	TargetID  int `db:"target_id"`
+	Count     int `db:"count"`
+}
+
+func (c NotesCount) ToModel() *models.NotesCount {
+	count := models.NotesCount(c)
+	return &count
+}
+
+type NoteAttachment struct {
+	ID          int       `db:"id"`
+	NoteID      int       `db:"note_id"`
+	MissionID   int       `db:"mission_id"`
+	TargetID    int       `db:"target_id"`
+	FileName    string    `db:"file_name"`
+	ContentType string    `db:"content_type"`
+	Size        int64     `db:"size"`
+	StorageKey  string    `db:"storage_key"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+func (a NoteAttachment) ToModel() *models.NoteAttachment {
+	attachment := models.NoteAttachment(a)
+	return &attachment
+}
+
+type NoteSearchResult struct {
+	Note
+	TargetName string  `db:"target_name"`
+	Country    string  `db:"country"`
+	Rank       float64 `db:"rank"`
+	// snippets are made of decrypted contents
+	Snippet string `db:"-"`
+}
+
+func (r NoteSearchResult) ToModel() *models.NoteSearchResult {
+	return &models.NoteSearchResult{
+		Note:       r.Note.ToModel(),
+		TargetName: r.TargetName,
+		Country:    r.Country,
+		Rank:       r.Rank,
+		Snippet:    r.Snippet,
+	}
+}
+
+// NoteRevision content must be decrypted before converting to model
+type NoteRevision struct {
+	ID        int `db:"id"`
+	NoteID    int `db:"note_id"`
+	MissionID int `db:"mission_id"`
+	TargetID  int `db:"target_id"`
+	Revision  int `db:"revision"`
+	EncryptedContent
+	Editor    string    `db:"editor"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (r NoteRevision) ToModel() *models.NoteRevision {
+	return &models.NoteRevision{
+		ID:        r.ID,
+		NoteID:    r.NoteID,
+		MissionID: r.MissionID,
+		TargetID:  r.TargetID,
+		Revision:  r.Revision,
+		Content:   r.Content,
+		Editor:    r.Editor,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+type CatStats struct {
+	CatID                    int `db:"cat_id"`
+	CountryMissions          int `db:"country_missions"`
+	CountryMissionsCompleted int `db:"country_missions_completed"`
+}
+
+func (s CatStats) ToModel() *models.CatStats {
+	stats := models.CatStats(s)
+	return &stats
+}
+
+type SimilarTarget struct {
+	Target
+	KeyIndex int `db:"key_index"`
+}
+
+func (t SimilarTarget) ToModel() *models.SimilarTarget {
+	return &models.SimilarTarget{
+		Target:   t.Target.ToModel(),
+		KeyIndex: t.KeyIndex,
+	}
+}
+
+type Person struct {
+	ID        int       `db:"id"`
+	Name      string    `db:"name"`
+	Country   string    `db:"country"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (p Person) ToModel() *models.Person {
+	person := models.Person(p)
+	return &person
+}
+
+type CountryStats struct {
+	Country           string `db:"country"`
+	OpenTargets       int    `db:"open_targets"`
+	CompletedTargets  int    `db:"completed_targets"`
+	OpenMissions      int    `db:"open_missions"`
+	CompletedMissions int    `db:"completed_missions"`
+}
+
+func (s CountryStats) ToModel() *models.CountryStats {
+	return &models.CountryStats{
+		Country:           s.Country,
+		OpenTargets:       s.OpenTargets,
+		CompletedTargets:  s.CompletedTargets,
+		OpenMissions:      s.OpenMissions,
+		CompletedMissions: s.CompletedMissions,
+	}
+}
+
+type Event struct {
+	ID        int              `db:"id"`
+	MissionID int              `db:"mission_id"`
+	Type      models.EventType `db:"type"`
+	Actor     string           `db:"actor"`
+	Payload   map[string]any   `db:"payload"`
+	CreatedAt time.Time        `db:"created_at"`
+}
+
+func (e Event) ToModel() *models.Event {
+	event := models.Event(e)
+	return &event
+}
+
+type Assignment struct {
+	ID           int        `db:"id"`
+	MissionID    int        `db:"mission_id"`
+	CatID        int        `db:"cat_id"`
+	CatName      string     `db:"cat_name"`
+	CatSalary    int        `db:"cat_salary"`
+	AssignedAt   time.Time  `db:"assigned_at"`
+	UnassignedAt *time.Time `db:"unassigned_at"`
+}
+
+func (a Assignment) ToModel() *models.Assignment {
+	assignment := models.Assignment(a)
+	return &assignment
 }
@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"strings"
+	"unicode"
+)
+
+// searchConfig is the text search configuration notes are indexed with
+const searchConfig = "english"
+
+// searchHeadlineOptions marks matches in snippets with <mark> tags
+const searchHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2"
+
+// snippetEscaper escapes contents before highlighting, so marks are the only markup in snippets.
+// Only named entities are used, text search parser keeps them whole.
+var snippetEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// toTSQuery converts user's search query into to_tsquery syntax, returning an empty string if there is nothing to search.
+// Terms are matched all together, "quoted phrases" match words next to each other and words ending with * match prefixes.
+// Punctuation is dropped, so user's input can't break the tsquery syntax.
+func toTSQuery(q string) string {
+	var terms []string
+
+	for i, part := range strings.Split(q, `"`) {
+		// odd parts are inside quotes
+		if i%2 == 1 {
+			if term := tsQueryPhrase(strings.Fields(part)); term != "" {
+				terms = append(terms, term)
+			}
+
+			continue
+		}
+
+		for _, word := range strings.Fields(part) {
+			if term := tsQueryPhrase([]string{word}); term != "" {
+				terms = append(terms, term)
+			}
+		}
+	}
+
+	return strings.Join(terms, " & ")
+}
+
+// tsQueryPhrase joins lexemes of the words with the followed-by operator
+func tsQueryPhrase(words []string) string {
+	var lexemes []string
+
+	for _, word := range words {
+		wordLexemes := strings.FieldsFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(wordLexemes) == 0 {
+			continue
+		}
+
+		if strings.HasSuffix(word, "*") {
+			wordLexemes[len(wordLexemes)-1] += ":*"
+		}
+
+		lexemes = append(lexemes, wordLexemes...)
+	}
+
+	switch len(lexemes) {
+	case 0:
+		return ""
+	case 1:
+		return lexemes[0]
+	default:
+		return "(" + strings.Join(lexemes, " <-> ") + ")"
+	}
+}
@@ -0,0 +1,29 @@
+package postgres
+
+import "testing"
+
+func TestToTSQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		q    string
+		want string
+	}{
+		{name: "empty", q: "", want: ""},
+		{name: "punctuation only", q: `!!! & | "" ()`, want: ""},
+		{name: "words", q: "coffee  shop", want: "coffee & shop"},
+		{name: "phrase", q: `"times square" coffee`, want: "(times <-> square) & coffee"},
+		{name: "prefix", q: "coff*", want: "coff:*"},
+		{name: "prefix in phrase", q: `"times squ*"`, want: "(times <-> squ:*)"},
+		{name: "operators are dropped", q: "a&b | !c:*", want: "(a <-> b) & c:*"},
+		{name: "unclosed quote", q: `coffee "times square`, want: "coffee & (times <-> square)"},
+		{name: "unicode letters", q: "Київ café", want: "Київ & café"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toTSQuery(tt.q); got != tt.want {
+				t.Errorf("toTSQuery(%q) = %q, want %q", tt.q, got, tt.want)
+			}
+		})
+	}
+}
@@ -11,9 +11,17 @@ import (
 	"github.com/illiafox/spy-cat-test-assignment/app/internal/repository/postgres/schema"
 	"github.com/illiafox/spy-cat-test-assignment/app/internal/service/dto"
 	"github.com/illiafox/spy-cat-test-assignment/app/pkg/poolwrapper"
+	"github.com/illiafox/spy-cat-test-assignment/app/pkg/textnorm"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+const targetColumns = `id, mission_id, is_completed, name, country, city, person_id, risk_level, priority, latitude, longitude,
+	reopened_at, reopened_by, reopen_reason, created_at, updated_at`
+
+// unique index preventing targets with the same name and country within a mission
+const targetsNormalizedNameKey = "targets_mission_id_normalized_name_country_key"
+
 type TargetsRepository struct {
 	db *poolwrapper.Pool
 }
@@ -22,17 +30,54 @@ func NewTargetsRepository(db *poolwrapper.Pool) *TargetsRepository {
 	return &TargetsRepository{db: db}
 }
 
-func (r *TargetsRepository) Create(ctx context.Context, missionID int, lastTargetID int, targets []dto.CreateTargetParams) error {
-	builder := sqlbuilder.InsertInto("targets").Cols("id", "mission_id", "name", "country")
-	for _, target := range targets {
-		lastTargetID++
-		builder.Values(lastTargetID, missionID, target.Name, target.Country)
+// Create allocates ids from the mission's target sequence, so ids of deleted targets are never reused.
+// The sequence row stays locked until the end of the transaction, serializing concurrent inserts.
+func (r *TargetsRepository) Create(ctx context.Context, missionID int, targets []dto.CreateTargetParams) error {
+	const allocateQuery = `UPDATE missions SET next_target_id = next_target_id + $2
+		WHERE id = $1 RETURNING next_target_id - $2`
+
+	var firstTargetID int
+
+	err := r.db.QueryRow(ctx, allocateQuery, missionID, len(targets)).Scan(&firstTargetID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return apperrors.MissionNotFound(missionID)
+		}
+
+		return apperrors.Internal(err).Wrap("allocate target ids").
+			WithMetadata("query", allocateQuery).
+			WithMetadata("mission_id", missionID)
+	}
+
+	builder := sqlbuilder.InsertInto("targets").
+		Cols("id", "mission_id", "name", "normalized_name", "country", "city", "person_id",
+			"risk_level", "priority", "latitude", "longitude")
+
+	names := make([]string, len(targets))
+	countries := make([]string, len(targets))
+	for i, target := range targets {
+		names[i] = textnorm.Name(target.Name)
+		countries[i] = target.Country
+
+		builder.Values(firstTargetID+i, missionID, target.Name, names[i], target.Country,
+			target.City, target.PersonID, target.RiskLevel, target.Priority, target.Latitude, target.Longitude)
 	}
 
 	query, args := builder.Build()
 
-	_, err := r.db.Exec(ctx, query, args...)
+	// inserted within a savepoint, so conflicting targets can be looked up after a unique violation
+	err = r.db.TxFunc(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		_, err := r.db.Exec(ctx, query, args...)
+		return err
+	})
 	if err != nil {
+		if isUniqueViolation(err, targetsNormalizedNameKey) {
+			return r.duplicateError(ctx, "create targets", `SELECT t.id FROM targets t
+				JOIN UNNEST($2::TEXT[], $3::TEXT[]) AS k(normalized_name, country)
+					ON t.normalized_name = k.normalized_name AND t.country = k.country
+				WHERE t.mission_id = $1 ORDER BY t.id`, missionID, names, countries)
+		}
+
 		return apperrors.Internal(err).Wrap("create targets").
 			WithMetadata("query", query).
 			WithMetadata("args", args)
@@ -41,6 +86,23 @@ func (r *TargetsRepository) Create(ctx context.Context, missionID int, lastTarge
 	return nil
 }
 
+// duplicateError looks up ids of targets violating the unique name index with the query
+func (r *TargetsRepository) duplicateError(ctx context.Context, msg string, query string, args ...any) error {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return apperrors.Internal(err).Wrap(msg+": find duplicate targets: pgx: query").
+			WithMetadata("query", query).
+			WithMetadata("args", args)
+	}
+
+	targetIDs, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return apperrors.Internal(err).Wrap(msg + ": find duplicate targets: pgx.CollectRows")
+	}
+
+	return apperrors.DuplicateTarget(targetIDs).Wrap(msg)
+}
+
 func (r *TargetsRepository) Delete(ctx context.Context, missionID, targetID int) (err error) {
 	query := "DELETE FROM targets WHERE mission_id = $1 AND id = $2"
 
@@ -59,21 +121,88 @@ func (r *TargetsRepository) Delete(ctx context.Context, missionID, targetID int)
 	return nil
 }
 
-func (r *TargetsRepository) Update(ctx context.Context, missionID, targetID int, completed *bool) (err error) {
+func (r *TargetsRepository) Update(ctx context.Context, params dto.UpdateTargetParams) (err error) {
 	builder := sqlbuilder.Update("targets")
 	builder.Set(builder.Assign("updated_at", time.Now()))
 
-	if completed != nil {
-		builder.SetMore(builder.Assign("is_completed", *completed))
+	if params.IsCompleted != nil {
+		builder.SetMore(builder.Assign("is_completed", *params.IsCompleted))
+	}
+
+	var normalizedName *string
+	if params.Name != nil {
+		name := textnorm.Name(*params.Name)
+		normalizedName = &name
+
+		builder.SetMore(
+			builder.Assign("name", *params.Name),
+			builder.Assign("normalized_name", name),
+		)
+	}
+
+	if params.Country != nil {
+		builder.SetMore(builder.Assign("country", *params.Country))
+	}
+
+	if params.City != nil {
+		builder.SetMore(builder.Assign("city", *params.City))
+	}
+
+	if params.RiskLevel != nil {
+		builder.SetMore(builder.Assign("risk_level", *params.RiskLevel))
+	}
+
+	if params.Priority != nil {
+		builder.SetMore(builder.Assign("priority", *params.Priority))
+	}
+
+	if params.Latitude != nil && params.Longitude != nil {
+		builder.SetMore(
+			builder.Assign("latitude", *params.Latitude),
+			builder.Assign("longitude", *params.Longitude),
+		)
 	}
 
 	query, args := builder.Where(
-		builder.Equal("mission_id", missionID),
-		builder.Equal("id", targetID),
+		builder.Equal("mission_id", params.MissionID),
+		builder.Equal("id", params.TargetID),
 	).Build()
 
 	//
 
+	// updated within a savepoint, so conflicting targets can be looked up after a unique violation
+	var res pgconn.CommandTag
+	err = r.db.TxFunc(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		res, err = r.db.Exec(ctx, query, args...)
+		return err
+	})
+	if err != nil {
+		if isUniqueViolation(err, targetsNormalizedNameKey) {
+			return r.duplicateError(ctx, "update target", `SELECT o.id FROM targets t
+				JOIN targets o ON o.mission_id = t.mission_id AND o.id <> t.id
+				WHERE t.mission_id = $1 AND t.id = $2
+					AND o.normalized_name = COALESCE($3, t.normalized_name) AND o.country = COALESCE($4, t.country)
+				ORDER BY o.id`, params.MissionID, params.TargetID, normalizedName, params.Country)
+		}
+
+		return apperrors.Internal(err).Wrap("pgx: exec").
+			WithMetadata("query", query).
+			WithMetadata("args", args)
+	}
+
+	if res.RowsAffected() == 0 {
+		return apperrors.TargetNotFound(params.TargetID)
+	}
+
+	return nil
+}
+
+func (r *TargetsRepository) Reopen(ctx context.Context, params dto.ReopenParams) (err error) {
+	const query = `UPDATE targets SET is_completed = false,
+		reopened_at = NOW(), reopened_by = $3, reopen_reason = $4, updated_at = NOW()
+		WHERE mission_id = $1 AND id = $2`
+	args := []any{params.MissionID, params.TargetID, params.ReopenedBy, params.Reason}
+
 	res, err := r.db.Exec(ctx, query, args...)
 	if err != nil {
 		return apperrors.Internal(err).Wrap("pgx: exec").
@@ -82,7 +211,7 @@ func (r *TargetsRepository) Update(ctx context.Context, missionID, targetID int,
 	}
 
 	if res.RowsAffected() == 0 {
-		return apperrors.TargetNotFound(targetID)
+		return apperrors.TargetNotFound(params.TargetID)
 	}
 
 	return nil
@@ -91,7 +220,7 @@ func (r *TargetsRepository) Update(ctx context.Context, missionID, targetID int,
 func (r *TargetsRepository) All(ctx context.Context, missionID int) ([]*models.Target, error) {
 	var schemaTargets []schema.Target
 
-	builder := sqlbuilder.Select("id", "mission_id", "is_completed", "name", "country", "created_at", "updated_at").
+	builder := sqlbuilder.Select(targetColumns).
 		From("targets").OrderBy("created_at").Desc()
 	builder.Where(builder.Equal("mission_id", missionID))
 
@@ -117,11 +246,220 @@ func (r *TargetsRepository) All(ctx context.Context, missionID int) ([]*models.T
 	return targets, nil
 }
 
+// AllByMissions returns targets of all given missions with a single query
+func (r *TargetsRepository) AllByMissions(ctx context.Context, missionIDs []int) ([]*models.Target, error) {
+	var schemaTargets []schema.Target
+
+	const query = `SELECT ` + targetColumns + ` FROM targets WHERE mission_id = ANY($1) ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(ctx, query, missionIDs)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("pgx: query").
+			WithMetadata("query", query).
+			WithMetadata("mission_ids", missionIDs)
+	}
+
+	schemaTargets, err = pgx.CollectRows(rows, pgx.RowToStructByName[schema.Target])
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectRows")
+	}
+
+	targets := make([]*models.Target, len(schemaTargets))
+	for i := range schemaTargets {
+		targets[i] = schemaTargets[i].ToModel()
+	}
+
+	return targets, nil
+}
+
+func (r *TargetsRepository) Filter(ctx context.Context, params dto.GetTargetsParams) ([]*models.Target, error) {
+	var schemaTargets []schema.Target
+
+	builder := sqlbuilder.Select(targetColumns).
+		From("targets").OrderBy("mission_id", "id")
+
+	if params.IsCompleted != nil {
+		builder.Where(builder.Equal("is_completed", *params.IsCompleted))
+	}
+
+	query, args := builder.Build()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("pgx: query").
+			WithMetadata("query", query).
+			WithMetadata("args", args)
+	}
+
+	schemaTargets, err = pgx.CollectRows(rows, pgx.RowToStructByName[schema.Target])
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectRows")
+	}
+
+	targets := make([]*models.Target, len(schemaTargets))
+	for i := range schemaTargets {
+		targets[i] = schemaTargets[i].ToModel()
+	}
+
+	return targets, nil
+}
+
+// Nearby returns located targets of open missions within the radius, closest first.
+// Distance is the great-circle one, calculated with haversine formula.
+func (r *TargetsRepository) Nearby(ctx context.Context, params dto.NearbyTargetsParams) ([]*models.NearbyTarget, error) {
+	var schemaTargets []schema.NearbyTarget
+
+	const query = `SELECT ` + targetColumns + `, distance_km FROM (
+		SELECT t.*, 2 * 6371 * ASIN(LEAST(1, SQRT(
+			POWER(SIN(RADIANS(t.latitude - $1) / 2), 2) +
+			COS(RADIANS($1)) * COS(RADIANS(t.latitude)) * POWER(SIN(RADIANS(t.longitude - $2) / 2), 2)
+		))) AS distance_km
+		FROM targets t JOIN missions m ON m.id = t.mission_id
+		WHERE NOT m.is_completed AND t.latitude IS NOT NULL AND t.longitude IS NOT NULL
+	) nearby WHERE distance_km <= $3 ORDER BY distance_km`
+	args := []any{params.Latitude, params.Longitude, params.RadiusKm}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("pgx: query").
+			WithMetadata("query", query).
+			WithMetadata("args", args)
+	}
+
+	schemaTargets, err = pgx.CollectRows(rows, pgx.RowToStructByName[schema.NearbyTarget])
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectRows")
+	}
+
+	targets := make([]*models.NearbyTarget, len(schemaTargets))
+	for i := range schemaTargets {
+		targets[i] = schemaTargets[i].ToModel()
+	}
+
+	return targets, nil
+}
+
+func (r *TargetsRepository) LinkPerson(ctx context.Context, missionID, targetID, personID int) (err error) {
+	const query = `UPDATE targets SET person_id = $3, updated_at = NOW() WHERE mission_id = $1 AND id = $2`
+	args := []any{missionID, targetID, personID}
+
+	res, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return apperrors.Internal(err).Wrap("pgx: exec").
+			WithMetadata("query", query).
+			WithMetadata("args", args)
+	}
+
+	if res.RowsAffected() == 0 {
+		return apperrors.TargetNotFound(targetID)
+	}
+
+	return nil
+}
+
+func (r *TargetsRepository) AllByPerson(ctx context.Context, personID int) ([]*models.Target, error) {
+	var schemaTargets []schema.Target
+
+	const query = `SELECT ` + targetColumns + ` FROM targets WHERE person_id = $1 ORDER BY created_at`
+
+	rows, err := r.db.Query(ctx, query, personID)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("pgx: query").
+			WithMetadata("query", query).
+			WithMetadata("person_id", personID)
+	}
+
+	schemaTargets, err = pgx.CollectRows(rows, pgx.RowToStructByName[schema.Target])
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectRows")
+	}
+
+	targets := make([]*models.Target, len(schemaTargets))
+	for i := range schemaTargets {
+		targets[i] = schemaTargets[i].ToModel()
+	}
+
+	return targets, nil
+}
+
+// Similar finds targets of other missions sharing the name and country with any of the keys.
+// Names are compared after normalization, see textnorm.Name.
+func (r *TargetsRepository) Similar(ctx context.Context, missionID int, keys []dto.CreateTargetParams) ([]*models.SimilarTarget, error) {
+	var schemaTargets []schema.SimilarTarget
+
+	const query = `SELECT ` + targetColumns + `, key_index FROM (
+		SELECT t.*, k.key_index - 1 AS key_index
+		FROM UNNEST($1::TEXT[], $2::TEXT[]) WITH ORDINALITY AS k(key_name, key_country, key_index)
+		JOIN targets t ON t.country = k.key_country AND t.normalized_name = k.key_name
+		WHERE t.mission_id <> $3
+	) matches ORDER BY key_index, mission_id, id`
+
+	names := make([]string, len(keys))
+	countries := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = textnorm.Name(key.Name)
+		countries[i] = key.Country
+	}
+
+	args := []any{names, countries, missionID}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("pgx: query").
+			WithMetadata("query", query).
+			WithMetadata("args", args)
+	}
+
+	schemaTargets, err = pgx.CollectRows(rows, pgx.RowToStructByName[schema.SimilarTarget])
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectRows")
+	}
+
+	targets := make([]*models.SimilarTarget, len(schemaTargets))
+	for i := range schemaTargets {
+		targets[i] = schemaTargets[i].ToModel()
+	}
+
+	return targets, nil
+}
+
+// CountryStats counts targets and missions having at least one target per country
+func (r *TargetsRepository) CountryStats(ctx context.Context) ([]*models.CountryStats, error) {
+	var schemaStats []schema.CountryStats
+
+	const query = `SELECT t.country,
+			COUNT(*) FILTER (WHERE NOT t.is_completed) AS open_targets,
+			COUNT(*) FILTER (WHERE t.is_completed) AS completed_targets,
+			COUNT(DISTINCT t.mission_id) FILTER (WHERE NOT m.is_completed) AS open_missions,
+			COUNT(DISTINCT t.mission_id) FILTER (WHERE m.is_completed) AS completed_missions
+		FROM targets t
+		JOIN missions m ON m.id = t.mission_id
+		GROUP BY t.country
+		ORDER BY t.country`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("pgx: query").
+			WithMetadata("query", query)
+	}
+
+	schemaStats, err = pgx.CollectRows(rows, pgx.RowToStructByName[schema.CountryStats])
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.Internal(err).Wrap("pgx.CollectRows")
+	}
+
+	stats := make([]*models.CountryStats, len(schemaStats))
+	for i := range schemaStats {
+		stats[i] = schemaStats[i].ToModel()
+	}
+
+	return stats, nil
+}
+
 func (r *TargetsRepository) One(ctx context.Context, missionID int, targetID int) (*models.Target, error) {
 	var target schema.Target
 
-	const query = `SELECT id, mission_id, is_completed, name, country, created_at, updated_at
-		FROM targets WHERE mission_id = $1 AND id = $2 `
+	const query = `SELECT ` + targetColumns + ` FROM targets WHERE mission_id = $1 AND id = $2`
 
 	rows, err := r.db.Query(ctx, query, missionID, targetID)
 	if err != nil {
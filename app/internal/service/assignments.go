@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+)
+
+func (s Service) GetMissionAssignments(ctx context.Context, missionID int) (out []*models.Assignment, err error) {
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err = s.missionsRepository.One(ctx, missionID)
+		if err != nil {
+			return fmt.Errorf("get mission %d: %w", missionID, err)
+		}
+
+		out, err = s.assignmentsRepository.AllByMission(ctx, missionID)
+		if err != nil {
+			return fmt.Errorf("assignments repository: all by mission: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("within transaction: %w", err)
+	}
+
+	return out, nil
+}
+
+func (s Service) GetCatAssignments(ctx context.Context, catID int) (out []*models.Assignment, err error) {
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err = s.catsRepository.One(ctx, catID)
+		if err != nil {
+			return fmt.Errorf("get cat %d: %w", catID, err)
+		}
+
+		out, err = s.assignmentsRepository.AllByCat(ctx, catID)
+		if err != nil {
+			return fmt.Errorf("assignments repository: all by cat: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("within transaction: %w", err)
+	}
+
+	return out, nil
+}
+
+// recordAssignment closes the current assignment of the mission and opens a new one for catID.
+// Nil catID only closes the current assignment, assigning the same cat again keeps history untouched.
+func (s Service) recordAssignment(ctx context.Context, mission *models.Mission, catID *int) error {
+	if catID != nil && mission.AssignedCatID != nil && *catID == *mission.AssignedCatID {
+		return nil
+	}
+
+	if mission.AssignedCatID != nil {
+		err := s.assignmentsRepository.Close(ctx, mission.ID)
+		if err != nil {
+			return fmt.Errorf("close assignment of mission %d: %w", mission.ID, err)
+		}
+	}
+
+	if catID != nil {
+		err := s.assignmentsRepository.Open(ctx, mission.ID, *catID)
+		if err != nil {
+			return fmt.Errorf("open assignment of mission %d: %w", mission.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/apperrors"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/service/dto"
+)
+
+// sniffLength is the number of bytes http.DetectContentType considers
+const sniffLength = 512
+
+// maxFileNameLength is the length of file_name column
+const maxFileNameLength = 255
+
+// AddNoteAttachment stores the file attached to the note, its content type is detected from the contents.
+// Attachments are frozen along with notes.
+func (s Service) AddNoteAttachment(ctx context.Context, params dto.CreateNoteAttachmentParams) (attachmentID int, err error) {
+	upload, err := s.prepareAttachment(params.File)
+	if err != nil {
+		return -1, err
+	}
+
+	var stored *models.NoteAttachment
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := s.checkNotesWritable(ctx, params.MissionID, params.TargetID)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.notesRepository.One(ctx, params.MissionID, params.TargetID, params.NoteID)
+		if err != nil {
+			return fmt.Errorf("get note %d: %w", params.NoteID, err)
+		}
+
+		stored, err = s.storeAttachment(ctx, params.MissionID, params.TargetID, params.NoteID, upload)
+
+		return err
+	})
+	if err != nil {
+		if stored != nil {
+			s.deleteBlobs(ctx, stored)
+		}
+
+		return -1, fmt.Errorf("within transaction: %w", err)
+	}
+
+	return stored.ID, nil
+}
+
+// attachmentUpload is a file checked to be attached to a note
+type attachmentUpload struct {
+	fileName    string
+	contentType string
+	size        int64
+	content     io.Reader
+}
+
+// prepareAttachment checks file's name, size and content type, which is detected from the contents
+func (s Service) prepareAttachment(file dto.AttachmentFile) (*attachmentUpload, error) {
+	fileName := filepath.Base(file.FileName)
+	if utf8.RuneCountInString(fileName) > maxFileNameLength {
+		return nil, apperrors.InvalidRequest(fmt.Errorf(
+			"file name must be at most %d characters long", maxFileNameLength,
+		))
+	}
+
+	if file.Size > s.attachments.MaxSize {
+		return nil, apperrors.AttachmentTooLarge(file.Size, s.attachments.MaxSize)
+	}
+
+	head := make([]byte, sniffLength)
+
+	n, err := io.ReadFull(file.Content, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, apperrors.Internal(err).Wrap("read attachment")
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, apperrors.Internal(err).Wrap("parse detected content type")
+	}
+
+	if !slices.Contains(s.attachments.ContentTypes, mediaType) {
+		return nil, apperrors.UnsupportedAttachmentType(mediaType)
+	}
+
+	return &attachmentUpload{
+		fileName:    fileName,
+		contentType: contentType,
+		size:        file.Size,
+		content:     io.MultiReader(bytes.NewReader(head), file.Content),
+	}, nil
+}
+
+// storeAttachment puts the upload into the blob store and attaches it to the note, it must be called within a transaction.
+// The returned attachment is not nil once its blob is stored, even on error, so the caller can delete it on rollback.
+func (s Service) storeAttachment(ctx context.Context, missionID, targetID, noteID int, upload *attachmentUpload) (*models.NoteAttachment, error) {
+	storageKey, err := newStorageKey(noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := &models.NoteAttachment{
+		NoteID:      noteID,
+		MissionID:   missionID,
+		TargetID:    targetID,
+		FileName:    upload.fileName,
+		ContentType: upload.contentType,
+		Size:        upload.size,
+		StorageKey:  storageKey,
+	}
+
+	err = s.blobStore.Put(ctx, storageKey, upload.content)
+	if err != nil {
+		return nil, fmt.Errorf("blob store: put %s: %w", storageKey, err)
+	}
+
+	attachment.ID, err = s.noteAttachmentsRepository.Create(ctx, attachment)
+	if err != nil {
+		return attachment, fmt.Errorf("create attachment of note %d: %w", noteID, err)
+	}
+
+	return attachment, s.recordEvent(ctx, missionID, models.EventAttachmentAdded, map[string]any{
+		"target_id":     targetID,
+		"note_id":       noteID,
+		"attachment_id": attachment.ID,
+		"file_name":     attachment.FileName,
+		"content_type":  attachment.ContentType,
+		"size":          attachment.Size,
+	})
+}
+
+func (s Service) GetNoteAttachments(ctx context.Context, missionID, targetID, noteID int) (out []*models.NoteAttachment, err error) {
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err = s.notesRepository.One(ctx, missionID, targetID, noteID)
+		if err != nil {
+			return fmt.Errorf("get note %d: %w", noteID, err)
+		}
+
+		out, err = s.noteAttachmentsRepository.All(ctx, noteID)
+		if err != nil {
+			return fmt.Errorf("get attachments of note %d: %w", noteID, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("within transaction: %w", err)
+	}
+
+	return out, nil
+}
+
+// GetNoteAttachment returns attachment's metadata and contents, the caller must close the contents
+func (s Service) GetNoteAttachment(ctx context.Context, missionID, targetID, noteID, attachmentID int) (*models.NoteAttachment, io.ReadCloser, error) {
+	_, err := s.notesRepository.One(ctx, missionID, targetID, noteID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get note %d: %w", noteID, err)
+	}
+
+	attachment, err := s.noteAttachmentsRepository.One(ctx, noteID, attachmentID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get attachment %d: %w", attachmentID, err)
+	}
+
+	content, err := s.blobStore.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("blob store: get %s: %w", attachment.StorageKey, err)
+	}
+
+	return attachment, content, nil
+}
+
+func (s Service) DeleteNoteAttachment(ctx context.Context, missionID, targetID, noteID, attachmentID int) (err error) {
+	var attachment *models.NoteAttachment
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := s.checkNotesWritable(ctx, missionID, targetID)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.notesRepository.One(ctx, missionID, targetID, noteID)
+		if err != nil {
+			return fmt.Errorf("get note %d: %w", noteID, err)
+		}
+
+		attachment, err = s.noteAttachmentsRepository.One(ctx, noteID, attachmentID)
+		if err != nil {
+			return fmt.Errorf("get attachment %d: %w", attachmentID, err)
+		}
+
+		err = s.noteAttachmentsRepository.Delete(ctx, noteID, attachmentID)
+		if err != nil {
+			return fmt.Errorf("delete attachment %d: %w", attachmentID, err)
+		}
+
+		return s.recordEvent(ctx, missionID, models.EventAttachmentDeleted, map[string]any{
+			"target_id":     targetID,
+			"note_id":       noteID,
+			"attachment_id": attachmentID,
+			"file_name":     attachment.FileName,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("within transaction: %w", err)
+	}
+
+	s.deleteBlobs(ctx, attachment)
+
+	return nil
+}
+
+// deleteBlobs removes contents of the attachments once they are unreachable, i.e. after the transaction is committed.
+// Failures leave orphaned blobs only, so they are ignored.
+func (s Service) deleteBlobs(ctx context.Context, attachments ...*models.NoteAttachment) {
+	for _, attachment := range attachments {
+		_ = s.blobStore.Delete(ctx, attachment.StorageKey)
+	}
+}
+
+func newStorageKey(noteID int) (string, error) {
+	suffix := make([]byte, 16)
+
+	_, err := rand.Read(suffix)
+	if err != nil {
+		return "", apperrors.Internal(err).Wrap("generate storage key")
+	}
+
+	return fmt.Sprintf("notes/%d/%s", noteID, hex.EncodeToString(suffix)), nil
+}
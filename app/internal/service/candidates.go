@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/apperrors"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/apperrors/codes"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/service/dto"
+)
+
+// Candidate score weights, they sum up to 1, so the score is within [0, 1]
+const (
+	experienceWeight = 0.35
+	costWeight       = 0.20
+	successWeight    = 0.30
+	workloadWeight   = 0.15
+
+	// experience above this value doesn't increase the score anymore
+	maxScoredExperienceYears = 20
+	// success rate of cats that have never worked in mission's countries
+	neutralSuccessRate = 0.5
+)
+
+func (s Service) GetMissionCandidates(ctx context.Context, missionID int) (out []*models.Candidate, err error) {
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		mission, err := s.GetMissionByID(ctx, missionID, dto.MissionExpand{})
+		if err != nil {
+			return fmt.Errorf("get mission %d: %w", missionID, err)
+		}
+
+		if mission.IsCompleted {
+			return apperrors.MissionAlreadyCompleted(missionID)
+		}
+
+		cats, err := s.catsRepository.All(ctx)
+		if err != nil {
+			return fmt.Errorf("cats repository: all: %w", err)
+		}
+
+		stats, err := s.catsRepository.Stats(ctx, missionCountries(mission))
+		if err != nil {
+			return fmt.Errorf("cats repository: stats: %w", err)
+		}
+
+		isCompleted := false
+		openMissions, err := s.missionsRepository.All(ctx, dto.GetMissionsParams{IsCompleted: &isCompleted})
+		if err != nil {
+			return fmt.Errorf("missions repository: all: %w", err)
+		}
+
+		catsMissions := make(map[int][]*models.Mission, len(cats))
+		for _, m := range openMissions {
+			if m.AssignedCatID != nil {
+				catsMissions[*m.AssignedCatID] = append(catsMissions[*m.AssignedCatID], m)
+			}
+		}
+
+		out = rankCandidates(mission.Mission, cats, stats, catsMissions,
+			s.rules.MaxOpenMissionsPerCat, s.minExperienceYears(mission.RiskLevel))
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("within transaction: %w", err)
+	}
+
+	return out, nil
+}
+
+func (s Service) AutoAssignMission(ctx context.Context, missionID int) (catID int, err error) {
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		candidates, err := s.GetMissionCandidates(ctx, missionID)
+		if err != nil {
+			return fmt.Errorf("get candidates: %w", err)
+		}
+
+		// candidates are checked again on assignment, the next one is tried if the check fails
+		for _, candidate := range candidates {
+			err = s.UpdateMissionByID(ctx, dto.UpdateMissionParams{
+				MissionID:     missionID,
+				AssignedCatID: &candidate.ID,
+			})
+			if apperrors.HasCode(err, codes.CatIsBusy, codes.CatDoubleBooked, codes.CatNotExperienced) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("assign cat %d: %w", candidate.ID, err)
+			}
+
+			catID = candidate.ID
+
+			return nil
+		}
+
+		return apperrors.NoCandidatesAvailable(missionID)
+	})
+	if err != nil {
+		return -1, fmt.Errorf("within transaction: %w", err)
+	}
+
+	return catID, nil
+}
+
+func missionCountries(mission *models.MissionFull) []string {
+	seen := make(map[string]struct{}, len(mission.Targets))
+	countries := make([]string, 0, len(mission.Targets))
+
+	for _, t := range mission.Targets {
+		if _, ok := seen[t.Country]; ok {
+			continue
+		}
+
+		seen[t.Country] = struct{}{}
+		countries = append(countries, t.Country)
+	}
+
+	return countries
+}
+
+// rankCandidates scores every available cat, except the already assigned one, and sorts them by score descending.
+// Cats failing the workload check against their open missions (catsMissions) are not available, see catWorkload.
+// Cats having less than minExperience years of experience are not available either.
+func rankCandidates(
+	mission *models.Mission, cats []*models.Cat, stats []*models.CatStats,
+	catsMissions map[int][]*models.Mission, maxOpenMissions, minExperience int,
+) []*models.Candidate {
+	statsByCat := make(map[int]*models.CatStats, len(stats))
+	for _, st := range stats {
+		statsByCat[st.CatID] = st
+	}
+
+	minSalary := 0
+	for _, cat := range cats {
+		if minSalary == 0 || (cat.Salary > 0 && cat.Salary < minSalary) {
+			minSalary = cat.Salary
+		}
+	}
+
+	candidates := make([]*models.Candidate, 0, len(cats))
+	for _, cat := range cats {
+		if mission.AssignedCatID != nil && *mission.AssignedCatID == cat.ID {
+			continue
+		}
+
+		if int(cat.ExperienceYears) < minExperience {
+			continue
+		}
+
+		openMissions, err := catWorkload(cat.ID, catsMissions[cat.ID], mission, maxOpenMissions)
+		if err != nil {
+			continue
+		}
+
+		candidate := &models.Candidate{
+			Cat:          cat,
+			SuccessRate:  neutralSuccessRate,
+			OpenMissions: openMissions,
+		}
+
+		if st, ok := statsByCat[cat.ID]; ok && st.CountryMissions > 0 {
+			candidate.SuccessRate = float64(st.CountryMissionsCompleted) / float64(st.CountryMissions)
+		}
+
+		experience := float64(min(cat.ExperienceYears, maxScoredExperienceYears)) / maxScoredExperienceYears
+
+		cost := 1.0
+		if cat.Salary > 0 {
+			cost = float64(minSalary) / float64(cat.Salary)
+		}
+
+		workload := 1 / float64(1+candidate.OpenMissions)
+
+		candidate.Score = experienceWeight*experience +
+			costWeight*cost +
+			successWeight*candidate.SuccessRate +
+			workloadWeight*workload
+
+		candidates = append(candidates, candidate)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	return candidates
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/apperrors"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/service/dto"
+)
+
+// checkCompletionPolicy saves the closing report, if any, and ensures the target has enough evidence to be completed.
+// The closing report is a note like any other, so it must be authored by the cat assigned to the mission.
+func (s Service) checkCompletionPolicy(ctx context.Context, mission *models.Mission, params dto.CompleteTargetParams) error {
+	if params.ClosingReport == nil && s.rules.CompletionRequireReport {
+		return apperrors.CompletionPolicyNotMet(params.TargetID, "closing report is required")
+	}
+
+	if params.ClosingReport != nil {
+		err := checkNoteAuthor(mission, params.AuthorCatID)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.createNotes(ctx, dto.CreateNotesParams{
+			MissionID:   params.MissionID,
+			TargetID:    params.TargetID,
+			AuthorCatID: params.AuthorCatID,
+			Contents:    []string{*params.ClosingReport},
+		})
+		if err != nil {
+			return fmt.Errorf("add closing report: %w", err)
+		}
+
+		err = s.recordEvent(ctx, params.MissionID, models.EventNotesAdded, map[string]any{
+			"target_id":      params.TargetID,
+			"count":          1,
+			"author_cat_id":  *params.AuthorCatID,
+			"closing_report": true,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.rules.CompletionMinNotes == 0 {
+		return nil
+	}
+
+	counts, err := s.notesRepository.CountByMissions(ctx, []int{params.MissionID}, dto.NotesFilter{})
+	if err != nil {
+		return fmt.Errorf("count notes of mission %d: %w", params.MissionID, err)
+	}
+
+	notesCount := 0
+	for _, count := range counts {
+		if count.TargetID == params.TargetID {
+			notesCount = count.Count
+		}
+	}
+
+	if notesCount < s.rules.CompletionMinNotes {
+		return apperrors.CompletionPolicyNotMet(params.TargetID, fmt.Sprintf(
+			"at least %d notes are required, but target has %d", s.rules.CompletionMinNotes, notesCount,
+		))
+	}
+
+	return nil
+}
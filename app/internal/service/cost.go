@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/service/dto"
+)
+
+// cat's salary is monthly, mission cost is prorated by days
+const salaryPeriodDays = 30
+
+func (s Service) GetMissionCost(ctx context.Context, missionID int) (*models.MissionCost, error) {
+	mission, err := s.GetMissionByID(ctx, missionID, dto.MissionExpand{})
+	if err != nil {
+		return nil, fmt.Errorf("get mission %d: %w", missionID, err)
+	}
+
+	return mission.Cost, nil
+}
+
+// calculateMissionCost prorates salary of every cat assigned to the mission over the period it was assigned:
+// from the assignment till unassignment or completion, or till now for missions in progress.
+// Salaries are taken as they were at assignment time, so cats deleted since are counted as well.
+// Assignments must belong to the mission.
+func calculateMissionCost(mission *models.Mission, assignments []*models.Assignment, now time.Time) *models.MissionCost {
+	cost := &models.MissionCost{
+		MissionID:     mission.ID,
+		AssignedCatID: mission.AssignedCatID,
+		Budget:        mission.Budget,
+		Assignments:   make([]*models.AssignmentCost, 0, len(assignments)),
+	}
+
+	end := now
+	if mission.CompletedAt != nil {
+		end = *mission.CompletedAt
+	}
+
+	for _, assignment := range assignments {
+		if assignment.UnassignedAt == nil && mission.AssignedCatID != nil && assignment.CatID == *mission.AssignedCatID {
+			cost.MonthlySalary = assignment.CatSalary
+		}
+
+		assignmentEnd := end
+		if assignment.UnassignedAt != nil && assignment.UnassignedAt.Before(end) {
+			assignmentEnd = *assignment.UnassignedAt
+		}
+
+		days := max(assignmentEnd.Sub(assignment.AssignedAt).Hours()/24, 0)
+		assignmentCost := &models.AssignmentCost{
+			CatID:         assignment.CatID,
+			MonthlySalary: assignment.CatSalary,
+			Days:          days,
+			Cost:          int(math.Round(float64(assignment.CatSalary) * days / salaryPeriodDays)),
+		}
+
+		cost.Days += assignmentCost.Days
+		cost.Cost += assignmentCost.Cost
+		cost.Assignments = append(cost.Assignments, assignmentCost)
+	}
+
+	cost.OverBudget = mission.Budget != nil && cost.Cost > *mission.Budget
+
+	return cost
+}
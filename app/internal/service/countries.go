@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+)
+
+func (s Service) GetCountriesStats(ctx context.Context) ([]*models.CountryStats, error) {
+	stats, err := s.targetsRepository.CountryStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("targets repository: country stats: %w", err)
+	}
+
+	for _, st := range stats {
+		st.CountryInfo, _ = s.countryDirectory.Country(st.Country)
+	}
+
+	return stats, nil
+}
+
+// fillTargetsCountries attaches country name and region to the targets
+func (s Service) fillTargetsCountries(targets ...*models.Target) {
+	for _, target := range targets {
+		target.CountryInfo, _ = s.countryDirectory.Country(target.Country)
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/service/dto"
+)
+
+func (s Service) CreatePerson(ctx context.Context, params dto.CreatePersonParams) (personID int, err error) {
+	personID, err = s.personsRepository.Create(ctx, params)
+	if err != nil {
+		return -1, fmt.Errorf("persons repository: create: %w", err)
+	}
+
+	return personID, nil
+}
+
+// GetDossier aggregates every target linked to the person with their notes across all missions
+func (s Service) GetDossier(ctx context.Context, personID int) (out *models.Dossier, err error) {
+	out = new(models.Dossier)
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		out.Person, err = s.personsRepository.One(ctx, personID)
+		if err != nil {
+			return fmt.Errorf("get person %d: %w", personID, err)
+		}
+
+		targets, err := s.targetsRepository.AllByPerson(ctx, personID)
+		if err != nil {
+			return fmt.Errorf("targets repository: all by person: %w", err)
+		}
+
+		s.fillTargetsCountries(targets...)
+
+		out.Targets = make([]*models.TargetFull, len(targets))
+		for i, target := range targets {
+			out.Targets[i] = &models.TargetFull{Target: target}
+		}
+
+		return s.embedNotes(ctx, out.Targets, dto.NotesFilter{})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("within transaction: %w", err)
+	}
+
+	return out, nil
+}
+
+// LinkTargetToPerson adds the target to person's dossier, completed targets can be linked as well
+func (s Service) LinkTargetToPerson(ctx context.Context, personID, missionID, targetID int) error {
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := s.personsRepository.One(ctx, personID)
+		if err != nil {
+			return fmt.Errorf("get person %d: %w", personID, err)
+		}
+
+		err = s.targetsRepository.LinkPerson(ctx, missionID, targetID, personID)
+		if err != nil {
+			return fmt.Errorf("link target %d to person %d: %w", targetID, personID, err)
+		}
+
+		return s.recordEvent(ctx, missionID, models.EventTargetUpdated, map[string]any{
+			"target_id": targetID,
+			"person_id": personID,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("within transaction: %w", err)
+	}
+
+	return nil
+}
+
+// checkTargetsPersons ensures persons the new targets are linked to exist
+func (s Service) checkTargetsPersons(ctx context.Context, targets []dto.CreateTargetParams) error {
+	checked := make(map[int]struct{}, len(targets))
+
+	for _, target := range targets {
+		if target.PersonID == nil {
+			continue
+		}
+
+		if _, ok := checked[*target.PersonID]; ok {
+			continue
+		}
+
+		_, err := s.personsRepository.One(ctx, *target.PersonID)
+		if err != nil {
+			return fmt.Errorf("get person %d: %w", *target.PersonID, err)
+		}
+
+		checked[*target.PersonID] = struct{}{}
+	}
+
+	return nil
+}
@@ -1,5 +1,12 @@
 package dto
 
+import (
+	"io"
+	"time"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+)
+
 type CreateCatParams struct {
 	Name       string
 	Breed      string
@@ -15,26 +22,164 @@ type UpdateCatParams struct {
 }
 
 type GetMissionsParams struct {
-	CatID *int
+	CatID       *int
+	IsCompleted *bool
+	Expand      MissionExpand
+}
+
+// MissionExpand lists mission's relations to embed into the response
+type MissionExpand struct {
+	Targets bool
+	Notes   bool // implies Targets
+	Cat     bool
 }
 
 type CreateMissionParams struct {
-	Targets []CreateTargetParams
+	Budget   *int
+	StartsAt *time.Time
+	EndsAt   *time.Time
+	Targets  []CreateTargetParams
 }
 
 type UpdateMissionParams struct {
 	MissionID     int
 	AssignedCatID *int
+	Unassign      bool // removes assigned cat, ignored if AssignedCatID is set
 	IsCompleted   *bool
+	Budget        *int
+	StartsAt      *time.Time
+	EndsAt        *time.Time
 }
 
 type UpdateTargetParams struct {
 	MissionID   int
 	TargetID    int
 	IsCompleted *bool
+	Name        *string
+	Country     *string
+	City        *string
+	RiskLevel   *models.RiskLevel
+	Priority    *int
+	Latitude    *float64 // set together with Longitude
+	Longitude   *float64
 }
 
 type CreateTargetParams struct {
+	Name      string
+	Country   string
+	City      *string
+	PersonID  *int
+	RiskLevel models.RiskLevel
+	Priority  int
+	Latitude  *float64 // set together with Longitude
+	Longitude *float64
+}
+
+type CompleteTargetParams struct {
+	MissionID     int
+	TargetID      int
+	ClosingReport *string // added as the last note of the target
+	AuthorCatID   *int    // author of the closing report, must be the assigned cat
+}
+
+// CreateNotesParams creates notes with Contents, Attachments can be added along with a single note only
+type CreateNotesParams struct {
+	MissionID   int
+	TargetID    int
+	AuthorCatID *int
+	Contents    []string
+	Attachments []AttachmentFile
+}
+
+// NotesFilter filters notes in listings, nil fields are ignored
+type NotesFilter struct {
+	AuthorCatID *int
+}
+
+// GetNotesParams requests a page of target's notes older than the Before cursor
+type GetNotesParams struct {
+	MissionID int
+	TargetID  int
+	Filter    NotesFilter
+	Before    *models.NotesCursor
+	Limit     int
+}
+
+type CreateNoteAttachmentParams struct {
+	MissionID int
+	TargetID  int
+	NoteID    int
+	File      AttachmentFile
+}
+
+type AttachmentFile struct {
+	FileName string
+	Size     int64
+	Content  io.Reader
+}
+
+// SearchNotesParams filters notes matching Query, nil fields are ignored.
+// Notes created within [From, To) are matched.
+type SearchNotesParams struct {
+	Query     string
+	MissionID *int
+	TargetID  *int
+	Country   *string
+	From      *time.Time
+	To        *time.Time
+	Limit     int
+}
+
+// UpdateNoteParams changes the note on behalf of AuthorCatID, see DeleteNoteParams
+type UpdateNoteParams struct {
+	MissionID   int
+	TargetID    int
+	NoteID      int
+	AuthorCatID *int
+	Content     string
+}
+
+// DeleteNoteParams deletes the note on behalf of AuthorCatID, who must be both its author and the assigned cat
+type DeleteNoteParams struct {
+	MissionID   int
+	TargetID    int
+	NoteID      int
+	AuthorCatID *int
+}
+
+type NoteRevisionsDiffParams struct {
+	MissionID int
+	TargetID  int
+	NoteID    int
+	From      int
+	To        int
+}
+
+type CreatePersonParams struct {
 	Name    string
 	Country string
 }
+
+type GetTargetsParams struct {
+	IsCompleted *bool
+}
+
+type NearbyTargetsParams struct {
+	Latitude  float64
+	Longitude float64
+	RadiusKm  float64
+}
+
+type CreateEventParams struct {
+	MissionID int
+	Type      models.EventType
+	Actor     string
+	Payload   map[string]any
+}
+
+type ReopenParams struct {
+	MissionID  int
+	TargetID   int // ignored when reopening a mission
+	ReopenedBy string
+	Reason     string
+}
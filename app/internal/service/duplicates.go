@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/apperrors"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/service/dto"
+	"github.com/illiafox/spy-cat-test-assignment/app/pkg/textnorm"
+)
+
+// duplicateKey identifies a target within a mission, the same way the unique index on targets does
+type duplicateKey struct {
+	name    string
+	country string
+}
+
+// newDuplicateKey normalizes the name the same way it is stored in the index, see textnorm.Name
+func newDuplicateKey(name, country string) duplicateKey {
+	return duplicateKey{
+		name:    textnorm.Name(name),
+		country: country,
+	}
+}
+
+// checkDuplicateTargets fails if new targets duplicate each other or existing targets of the mission
+func checkDuplicateTargets(existing []*models.Target, newTargets []dto.CreateTargetParams) error {
+	existingByKey := make(map[duplicateKey][]int, len(existing))
+	for _, t := range existing {
+		key := newDuplicateKey(t.Name, t.Country)
+		existingByKey[key] = append(existingByKey[key], t.ID)
+	}
+
+	var conflictingIDs []int
+
+	seen := make(map[duplicateKey]int, len(newTargets))
+	for i, t := range newTargets {
+		key := newDuplicateKey(t.Name, t.Country)
+
+		if j, ok := seen[key]; ok {
+			return apperrors.DuplicateNewTargets([]int{j, i})
+		}
+		seen[key] = i
+
+		conflictingIDs = append(conflictingIDs, existingByKey[key]...)
+	}
+
+	if len(conflictingIDs) > 0 {
+		return apperrors.DuplicateTarget(conflictingIDs)
+	}
+
+	return nil
+}
+
+// checkRenamedTargetDuplicates fails if the target, after updating its name or country, duplicates another target of the mission
+func (s Service) checkRenamedTargetDuplicates(ctx context.Context, target *models.Target, params dto.UpdateTargetParams) error {
+	updated := dto.CreateTargetParams{Name: target.Name, Country: target.Country}
+	if params.Name != nil {
+		updated.Name = *params.Name
+	}
+	if params.Country != nil {
+		updated.Country = *params.Country
+	}
+
+	targets, err := s.targetsRepository.All(ctx, target.MissionID)
+	if err != nil {
+		return fmt.Errorf("targets repository: all: %w", err)
+	}
+
+	others := make([]*models.Target, 0, len(targets))
+	for _, t := range targets {
+		if t.ID != target.ID {
+			others = append(others, t)
+		}
+	}
+
+	return checkDuplicateTargets(others, []dto.CreateTargetParams{updated})
+}
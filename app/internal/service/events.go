@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/service/dto"
+	"github.com/illiafox/spy-cat-test-assignment/app/pkg/actor"
+)
+
+func (s Service) GetMissionTimeline(ctx context.Context, missionID int) (out []*models.Event, err error) {
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err = s.missionsRepository.One(ctx, missionID)
+		if err != nil {
+			return fmt.Errorf("get mission %d: %w", missionID, err)
+		}
+
+		out, err = s.eventsRepository.All(ctx, missionID)
+		if err != nil {
+			return fmt.Errorf("events repository: all: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("within transaction: %w", err)
+	}
+
+	return out, nil
+}
+
+// recordEvent appends an event to the mission's timeline, it must be called within the mutating transaction.
+func (s Service) recordEvent(ctx context.Context, missionID int, eventType models.EventType, payload map[string]any) error {
+	err := s.eventsRepository.Create(ctx, dto.CreateEventParams{
+		MissionID: missionID,
+		Type:      eventType,
+		Actor:     actor.Extract(ctx),
+		Payload:   payload,
+	})
+	if err != nil {
+		return fmt.Errorf("record '%s' event: %w", eventType, err)
+	}
+
+	return nil
+}
+
+func targetsPayload(targets []dto.CreateTargetParams) []map[string]any {
+	payload := make([]map[string]any, len(targets))
+	for i, t := range targets {
+		payload[i] = map[string]any{
+			"name":       t.Name,
+			"country":    t.Country,
+			"city":       t.City,
+			"person_id":  t.PersonID,
+			"risk_level": t.RiskLevel,
+			"priority":   t.Priority,
+			"latitude":   t.Latitude,
+			"longitude":  t.Longitude,
+		}
+	}
+
+	return payload
+}
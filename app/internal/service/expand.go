@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/service/dto"
+)
+
+// loadMissionsRelations fills requested relations and cost of the missions.
+// Every relation is loaded with a single query regardless of missions count.
+func (s Service) loadMissionsRelations(ctx context.Context, missions []*models.MissionFull, expand dto.MissionExpand) error {
+	if len(missions) == 0 {
+		return nil
+	}
+
+	missionIDs := make([]int, len(missions))
+	catIDs := make([]int, 0, len(missions))
+	for i, mission := range missions {
+		missionIDs[i] = mission.ID
+		if expand.Cat && mission.AssignedCatID != nil {
+			catIDs = append(catIDs, *mission.AssignedCatID)
+		}
+	}
+
+	// assignments history is required for cost calculation, it includes cats assigned before
+	assignments, err := s.assignmentsRepository.AllByMissions(ctx, missionIDs)
+	if err != nil {
+		return fmt.Errorf("assignments repository: all by missions: %w", err)
+	}
+
+	missionAssignments := make(map[int][]*models.Assignment, len(missions))
+	for _, assignment := range assignments {
+		missionAssignments[assignment.MissionID] = append(missionAssignments[assignment.MissionID], assignment)
+	}
+
+	catsByID := make(map[int]*models.Cat, len(catIDs))
+	if len(catIDs) > 0 {
+		cats, err := s.catsRepository.ByIDs(ctx, catIDs)
+		if err != nil {
+			return fmt.Errorf("cats repository: by ids: %w", err)
+		}
+
+		for _, cat := range cats {
+			catsByID[cat.ID] = cat
+		}
+	}
+
+	now := time.Now()
+	for _, mission := range missions {
+		var cat *models.Cat
+		if mission.AssignedCatID != nil {
+			cat = catsByID[*mission.AssignedCatID]
+		}
+
+		mission.Cost = calculateMissionCost(mission.Mission, missionAssignments[mission.ID], now)
+		if expand.Cat {
+			mission.Cat = cat
+		}
+	}
+
+	// targets are always loaded as well, they define mission's risk level
+	targets, err := s.targetsRepository.AllByMissions(ctx, missionIDs)
+	if err != nil {
+		return fmt.Errorf("targets repository: all by missions: %w", err)
+	}
+
+	missionTargets := make(map[int][]*models.Target, len(missions))
+	for _, target := range targets {
+		missionTargets[target.MissionID] = append(missionTargets[target.MissionID], target)
+	}
+
+	for _, mission := range missions {
+		mission.RiskLevel = missionRiskLevel(missionTargets[mission.ID])
+	}
+
+	if !expand.Targets && !expand.Notes {
+		return nil
+	}
+
+	targetsFull := make([]*models.TargetFull, 0, len(targets))
+	targetsByMission := make(map[int][]*models.TargetFull, len(missions))
+
+	s.fillTargetsCountries(targets...)
+
+	for _, target := range targets {
+		targetFull := &models.TargetFull{Target: target}
+
+		targetsFull = append(targetsFull, targetFull)
+		targetsByMission[target.MissionID] = append(targetsByMission[target.MissionID], targetFull)
+	}
+
+	for _, mission := range missions {
+		mission.Targets = targetsByMission[mission.ID]
+		if mission.Targets == nil {
+			mission.Targets = []*models.TargetFull{}
+		}
+	}
+
+	if !expand.Notes {
+		return nil
+	}
+
+	return s.embedNotes(ctx, targetsFull, dto.NotesFilter{})
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/service/dto"
+)
+
+func (s Service) GetMissionTargetLocations(ctx context.Context, missionID int) ([]*models.TargetLocation, error) {
+	mission, err := s.missionsRepository.One(ctx, missionID)
+	if err != nil {
+		return nil, fmt.Errorf("missions repository: one: %w", err)
+	}
+
+	targets, err := s.targetsRepository.All(ctx, missionID)
+	if err != nil {
+		return nil, fmt.Errorf("targets repository: all: %w", err)
+	}
+
+	locations := make([]*models.TargetLocation, len(targets))
+	for i, target := range targets {
+		locations[i] = s.locateTarget(target, mission)
+	}
+
+	return locations, nil
+}
+
+func (s Service) GetTargetLocations(ctx context.Context, params dto.GetTargetsParams) ([]*models.TargetLocation, error) {
+	targets, err := s.targetsRepository.Filter(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("targets repository: filter: %w", err)
+	}
+
+	missionIDs := make([]int, 0, len(targets))
+	for _, target := range targets {
+		if len(missionIDs) == 0 || missionIDs[len(missionIDs)-1] != target.MissionID {
+			missionIDs = append(missionIDs, target.MissionID)
+		}
+	}
+
+	missions, err := s.missionsRepository.ByIDs(ctx, missionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("missions repository: by ids: %w", err)
+	}
+
+	missionsByID := make(map[int]*models.Mission, len(missions))
+	for _, mission := range missions {
+		missionsByID[mission.ID] = mission
+	}
+
+	locations := make([]*models.TargetLocation, len(targets))
+	for i, target := range targets {
+		locations[i] = s.locateTarget(target, missionsByID[target.MissionID])
+	}
+
+	return locations, nil
+}
+
+// locateTarget prefers target's own coordinates and falls back to the centroid of its country
+func (s Service) locateTarget(target *models.Target, mission *models.Mission) *models.TargetLocation {
+	location := &models.TargetLocation{
+		Target:  target,
+		Mission: mission,
+	}
+
+	s.fillTargetsCountries(target)
+
+	if target.Latitude != nil && target.Longitude != nil {
+		location.Latitude = target.Latitude
+		location.Longitude = target.Longitude
+		location.Source = models.TargetLocationCoordinates
+
+		return location
+	}
+
+	if country := target.CountryInfo; country != nil {
+		location.Latitude = &country.Latitude
+		location.Longitude = &country.Longitude
+		location.Source = models.TargetLocationCountryCentroid
+	}
+
+	return location
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/apperrors"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/service/dto"
+	"github.com/illiafox/spy-cat-test-assignment/app/pkg/actor"
+	"github.com/illiafox/spy-cat-test-assignment/app/pkg/textdiff"
+)
+
+func (s Service) UpdateTargetNote(ctx context.Context, params dto.UpdateNoteParams) (err error) {
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		mission, err := s.checkNotesWritable(ctx, params.MissionID, params.TargetID)
+		if err != nil {
+			return err
+		}
+
+		note, err := s.notesRepository.One(ctx, params.MissionID, params.TargetID, params.NoteID)
+		if err != nil {
+			return fmt.Errorf("get note %d: %w", params.NoteID, err)
+		}
+
+		err = checkNoteChangeable(mission, note, params.AuthorCatID)
+		if err != nil {
+			return err
+		}
+
+		err = s.notesRepository.Update(ctx, params)
+		if err != nil {
+			return fmt.Errorf("update note %d: %w", params.NoteID, err)
+		}
+
+		err = s.noteRevisionsRepository.Create(ctx, []int{params.NoteID}, actor.Extract(ctx))
+		if err != nil {
+			return fmt.Errorf("create revision of note %d: %w", params.NoteID, err)
+		}
+
+		// contents are kept out of events since they may be encrypted at rest, revisions keep them instead
+		return s.recordEvent(ctx, params.MissionID, models.EventNoteUpdated, map[string]any{
+			"target_id": params.TargetID,
+			"note_id":   params.NoteID,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("within transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (s Service) DeleteTargetNote(ctx context.Context, params dto.DeleteNoteParams) (err error) {
+	var attachments []*models.NoteAttachment
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		mission, err := s.checkNotesWritable(ctx, params.MissionID, params.TargetID)
+		if err != nil {
+			return err
+		}
+
+		note, err := s.notesRepository.One(ctx, params.MissionID, params.TargetID, params.NoteID)
+		if err != nil {
+			return fmt.Errorf("get note %d: %w", params.NoteID, err)
+		}
+
+		err = checkNoteChangeable(mission, note, params.AuthorCatID)
+		if err != nil {
+			return err
+		}
+
+		attachments, err = s.noteAttachmentsRepository.All(ctx, params.NoteID)
+		if err != nil {
+			return fmt.Errorf("get attachments of note %d: %w", params.NoteID, err)
+		}
+
+		// attachments are deleted along with the note
+		err = s.notesRepository.Delete(ctx, params.MissionID, params.TargetID, params.NoteID)
+		if err != nil {
+			return fmt.Errorf("delete note %d: %w", params.NoteID, err)
+		}
+
+		return s.recordEvent(ctx, params.MissionID, models.EventNoteDeleted, map[string]any{
+			"target_id": params.TargetID,
+			"note_id":   params.NoteID,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("within transaction: %w", err)
+	}
+
+	s.deleteBlobs(ctx, attachments...)
+
+	return nil
+}
+
+// GetTargetNotes returns a page of target's notes, the newest first
+func (s Service) GetTargetNotes(ctx context.Context, params dto.GetNotesParams) (out *models.NotesPage, err error) {
+	out = new(models.NotesPage)
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err = s.targetsRepository.One(ctx, params.MissionID, params.TargetID)
+		if err != nil {
+			return fmt.Errorf("get target by id: %w", err)
+		}
+
+		// one more note tells whether there is the next page
+		notes, err := s.notesRepository.All(ctx, params.MissionID, params.TargetID, params.Filter, params.Before, params.Limit+1)
+		if err != nil {
+			return fmt.Errorf("get notes by target id %d: %w", params.TargetID, err)
+		}
+
+		if len(notes) > params.Limit {
+			notes = notes[:params.Limit]
+
+			last := notes[len(notes)-1]
+			out.NextBefore = &models.NotesCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		}
+
+		out.Notes = notes
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("within transaction: %w", err)
+	}
+
+	return out, nil
+}
+
+// embedNotes loads the latest notes of the targets, up to the embed limit per target, and counts all of them
+func (s Service) embedNotes(ctx context.Context, targets []*models.TargetFull, filter dto.NotesFilter) error {
+	type targetKey struct{ missionID, targetID int }
+	targetsByKey := make(map[targetKey]*models.TargetFull, len(targets))
+	missionIDs := make([]int, 0, len(targets))
+
+	for _, target := range targets {
+		target.Notes = []*models.Note{}
+		targetsByKey[targetKey{target.MissionID, target.ID}] = target
+		missionIDs = append(missionIDs, target.MissionID)
+	}
+
+	if len(targets) == 0 {
+		return nil
+	}
+
+	notes, err := s.notesRepository.AllByMissions(ctx, missionIDs, filter, s.notes.EmbedLimit)
+	if err != nil {
+		return fmt.Errorf("notes repository: all by missions: %w", err)
+	}
+
+	for _, note := range notes {
+		if target, ok := targetsByKey[targetKey{note.MissionID, note.TargetID}]; ok {
+			target.Notes = append(target.Notes, note)
+		}
+	}
+
+	counts, err := s.notesRepository.CountByMissions(ctx, missionIDs, filter)
+	if err != nil {
+		return fmt.Errorf("notes repository: count by missions: %w", err)
+	}
+
+	for _, count := range counts {
+		if target, ok := targetsByKey[targetKey{count.MissionID, count.TargetID}]; ok {
+			target.NotesCount = count.Count
+		}
+	}
+
+	return nil
+}
+
+func (s Service) SearchNotes(ctx context.Context, params dto.SearchNotesParams) ([]*models.NoteSearchResult, error) {
+	results, err := s.notesRepository.Search(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("notes repository: search: %w", err)
+	}
+
+	return results, nil
+}
+
+func (s Service) GetNoteRevisions(ctx context.Context, missionID, targetID, noteID int) ([]*models.NoteRevision, error) {
+	revisions, err := s.noteRevisionsRepository.All(ctx, missionID, targetID, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("get revisions of note %d: %w", noteID, err)
+	}
+
+	// revisions are kept after the note is deleted, so no revisions means the note never existed
+	if len(revisions) == 0 {
+		return nil, apperrors.NoteNotFound(noteID)
+	}
+
+	return revisions, nil
+}
+
+// GetNoteRevisionsDiff returns unified diff between contents of two revisions of the note
+func (s Service) GetNoteRevisionsDiff(ctx context.Context, params dto.NoteRevisionsDiffParams) (string, error) {
+	revisions, err := s.GetNoteRevisions(ctx, params.MissionID, params.TargetID, params.NoteID)
+	if err != nil {
+		return "", err
+	}
+
+	byNumber := make(map[int]*models.NoteRevision, len(revisions))
+	for _, revision := range revisions {
+		byNumber[revision.Revision] = revision
+	}
+
+	from, ok := byNumber[params.From]
+	if !ok {
+		return "", apperrors.NoteRevisionNotFound(params.NoteID, params.From)
+	}
+
+	to, ok := byNumber[params.To]
+	if !ok {
+		return "", apperrors.NoteRevisionNotFound(params.NoteID, params.To)
+	}
+
+	return textdiff.Unified(
+		fmt.Sprintf("revision %d", from.Revision),
+		fmt.Sprintf("revision %d", to.Revision),
+		from.Content, to.Content,
+	), nil
+}
+
+// createNotes adds notes to the target and stores their contents as the first revisions
+func (s Service) createNotes(ctx context.Context, params dto.CreateNotesParams) (noteIDs []int, err error) {
+	noteIDs, err = s.notesRepository.Create(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("add notes by target id %d: %w", params.TargetID, err)
+	}
+
+	err = s.noteRevisionsRepository.Create(ctx, noteIDs, actor.Extract(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("create revisions of notes %v: %w", noteIDs, err)
+	}
+
+	return noteIDs, nil
+}
+
+// checkNoteAuthor fails unless the author is the cat assigned to the mission, only that cat writes its notes
+func checkNoteAuthor(mission *models.Mission, authorCatID *int) error {
+	if authorCatID == nil {
+		return apperrors.InvalidRequest(errors.New("note author is required"))
+	}
+
+	if mission.AssignedCatID == nil || *mission.AssignedCatID != *authorCatID {
+		return apperrors.NoteAuthorNotAssigned(*authorCatID, mission.ID)
+	}
+
+	return nil
+}
+
+// checkNoteChangeable fails unless the note is changed by its author, who is still assigned to the mission.
+// Notes written before authors were recorded can be changed by the assigned cat.
+func checkNoteChangeable(mission *models.Mission, note *models.Note, authorCatID *int) error {
+	err := checkNoteAuthor(mission, authorCatID)
+	if err != nil {
+		return err
+	}
+
+	if note.AuthorCatID != nil && *note.AuthorCatID != *authorCatID {
+		return apperrors.PermissionDenied(fmt.Sprintf("note %d can be changed only by its author", note.ID))
+	}
+
+	return nil
+}
+
+// checkNotesWritable fails if notes of the target are frozen, because either the target or the mission is completed
+func (s Service) checkNotesWritable(ctx context.Context, missionID, targetID int) (*models.Mission, error) {
+	mission, err := s.missionsRepository.One(ctx, missionID)
+	if err != nil {
+		return nil, fmt.Errorf("get mission %d: %w", missionID, err)
+	}
+
+	if mission.IsCompleted && s.rules.FreezeNotesOnCompletion {
+		return nil, apperrors.MissionAlreadyCompleted(missionID)
+	}
+
+	target, err := s.targetsRepository.One(ctx, missionID, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("get target by id: %w", err)
+	}
+
+	if target.IsCompleted && s.rules.FreezeNotesOnCompletion {
+		return nil, apperrors.TargetAlreadyCompleted(targetID)
+	}
+
+	return mission, nil
+}
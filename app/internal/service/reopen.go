@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/apperrors"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/service/dto"
+	"github.com/illiafox/spy-cat-test-assignment/app/pkg/actor"
+)
+
+func (s Service) ReopenMission(ctx context.Context, missionID int, reason string) (err error) {
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		mission, err := s.missionsRepository.One(ctx, missionID)
+		if err != nil {
+			return fmt.Errorf("get mission %d: %w", missionID, err)
+		}
+
+		if !mission.IsCompleted {
+			return apperrors.MissionNotCompleted(missionID).Wrap("can't reopen")
+		}
+
+		err = s.missionsRepository.Reopen(ctx, dto.ReopenParams{
+			MissionID:  missionID,
+			ReopenedBy: actor.Extract(ctx),
+			Reason:     reason,
+		})
+		if err != nil {
+			return fmt.Errorf("reopen mission %d: %w", missionID, err)
+		}
+
+		return s.recordEvent(ctx, missionID, models.EventMissionReopened, map[string]any{
+			"reason": reason,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("within transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ReopenTarget unfreezes target's notes. Targets of completed missions can't be reopened,
+// the mission has to be reopened first.
+func (s Service) ReopenTarget(ctx context.Context, missionID, targetID int, reason string) (err error) {
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		mission, err := s.missionsRepository.One(ctx, missionID)
+		if err != nil {
+			return fmt.Errorf("get mission %d: %w", missionID, err)
+		}
+
+		if mission.IsCompleted {
+			return apperrors.MissionAlreadyCompleted(missionID).Wrap("can't reopen target")
+		}
+
+		target, err := s.targetsRepository.One(ctx, missionID, targetID)
+		if err != nil {
+			return fmt.Errorf("get target by id: %w", err)
+		}
+
+		if !target.IsCompleted {
+			return apperrors.TargetNotCompleted(targetID).Wrap("can't reopen")
+		}
+
+		err = s.targetsRepository.Reopen(ctx, dto.ReopenParams{
+			MissionID:  missionID,
+			TargetID:   targetID,
+			ReopenedBy: actor.Extract(ctx),
+			Reason:     reason,
+		})
+		if err != nil {
+			return fmt.Errorf("reopen target %d: %w", targetID, err)
+		}
+
+		return s.recordEvent(ctx, missionID, models.EventTargetReopened, map[string]any{
+			"target_id": targetID,
+			"reason":    reason,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("within transaction: %w", err)
+	}
+
+	return nil
+}
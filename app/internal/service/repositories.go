@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"io"
 
 	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
 	"github.com/illiafox/spy-cat-test-assignment/app/internal/service/dto"
@@ -15,31 +16,96 @@ type CatBreedChecker interface {
 	CheckBreed(ctx context.Context, breed string) (formattedBreed string, err error)
 }
 
+type CountryDirectory interface {
+	Country(code string) (*models.Country, bool)
+}
+
 type CatsRepository interface {
 	Create(ctx context.Context, params dto.CreateCatParams) (catID int, err error)
 	Delete(ctx context.Context, catID int) (err error)
 	Update(ctx context.Context, params dto.UpdateCatParams) (err error)
+	Lock(ctx context.Context, catID int) error
 	One(ctx context.Context, catID int) (*models.Cat, error)
 	All(ctx context.Context) ([]*models.Cat, error)
+	ByIDs(ctx context.Context, catIDs []int) ([]*models.Cat, error)
+	Stats(ctx context.Context, countries []string) ([]*models.CatStats, error)
 }
 
 type MissionsRepository interface {
-	Create(ctx context.Context) (missionID int, err error)
+	Create(ctx context.Context, params dto.CreateMissionParams) (missionID int, err error)
 	Delete(ctx context.Context, missionID int) (err error)
 	Update(ctx context.Context, params dto.UpdateMissionParams) (err error)
+	Reopen(ctx context.Context, params dto.ReopenParams) (err error)
+	UnassignCat(ctx context.Context, catID int) error
+	Lock(ctx context.Context, missionID int) error
 	One(ctx context.Context, missionID int) (*models.Mission, error)
+	ByIDs(ctx context.Context, missionIDs []int) ([]*models.Mission, error)
 	All(ctx context.Context, params dto.GetMissionsParams) ([]*models.Mission, error)
 }
 
 type TargetsRepository interface {
-	Create(ctx context.Context, missionID int, lastTargetID int, targets []dto.CreateTargetParams) error
+	Create(ctx context.Context, missionID int, targets []dto.CreateTargetParams) error
 	Delete(ctx context.Context, missionID int, targetID int) (err error)
-	Update(ctx context.Context, missionID int, targetID int, completed *bool) (err error)
+	Update(ctx context.Context, params dto.UpdateTargetParams) (err error)
+	Reopen(ctx context.Context, params dto.ReopenParams) (err error)
 	All(ctx context.Context, missionID int) ([]*models.Target, error)
+	AllByMissions(ctx context.Context, missionIDs []int) ([]*models.Target, error)
+	Filter(ctx context.Context, params dto.GetTargetsParams) ([]*models.Target, error)
+	CountryStats(ctx context.Context) ([]*models.CountryStats, error)
+	Similar(ctx context.Context, missionID int, keys []dto.CreateTargetParams) ([]*models.SimilarTarget, error)
+	LinkPerson(ctx context.Context, missionID int, targetID int, personID int) (err error)
+	AllByPerson(ctx context.Context, personID int) ([]*models.Target, error)
+	Nearby(ctx context.Context, params dto.NearbyTargetsParams) ([]*models.NearbyTarget, error)
 	One(ctx context.Context, missionID int, targetID int) (*models.Target, error)
 }
 
 type NotesRepository interface {
-	Create(ctx context.Context, missionID int, targetID int, contents []string) error
-	All(ctx context.Context, missionID int, targetID int) ([]*models.Note, error)
+	Create(ctx context.Context, params dto.CreateNotesParams) (noteIDs []int, err error)
+	Update(ctx context.Context, params dto.UpdateNoteParams) error
+	Delete(ctx context.Context, missionID int, targetID int, noteID int) error
+	One(ctx context.Context, missionID int, targetID int, noteID int) (*models.Note, error)
+	All(ctx context.Context, missionID int, targetID int, filter dto.NotesFilter, before *models.NotesCursor, limit int) ([]*models.Note, error)
+	AllByMissions(ctx context.Context, missionIDs []int, filter dto.NotesFilter, limitPerTarget int) ([]*models.Note, error)
+	CountByMissions(ctx context.Context, missionIDs []int, filter dto.NotesFilter) ([]*models.NotesCount, error)
+	Search(ctx context.Context, params dto.SearchNotesParams) ([]*models.NoteSearchResult, error)
+}
+
+type PersonsRepository interface {
+	Create(ctx context.Context, params dto.CreatePersonParams) (personID int, err error)
+	One(ctx context.Context, personID int) (*models.Person, error)
+}
+
+type NoteRevisionsRepository interface {
+	Create(ctx context.Context, noteIDs []int, editor string) error
+	All(ctx context.Context, missionID int, targetID int, noteID int) ([]*models.NoteRevision, error)
+}
+
+type NoteAttachmentsRepository interface {
+	Create(ctx context.Context, attachment *models.NoteAttachment) (attachmentID int, err error)
+	One(ctx context.Context, noteID int, attachmentID int) (*models.NoteAttachment, error)
+	All(ctx context.Context, noteID int) ([]*models.NoteAttachment, error)
+	AllByTarget(ctx context.Context, missionID int, targetID int) ([]*models.NoteAttachment, error)
+	AllByMission(ctx context.Context, missionID int) ([]*models.NoteAttachment, error)
+	Delete(ctx context.Context, noteID int, attachmentID int) error
+}
+
+// BlobStore keeps contents of attachments by keys
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type AssignmentsRepository interface {
+	Open(ctx context.Context, missionID int, catID int) error
+	Close(ctx context.Context, missionID int) error
+	CloseByCat(ctx context.Context, catID int) (missionIDs []int, err error)
+	AllByMission(ctx context.Context, missionID int) ([]*models.Assignment, error)
+	AllByCat(ctx context.Context, catID int) ([]*models.Assignment, error)
+	AllByMissions(ctx context.Context, missionIDs []int) ([]*models.Assignment, error)
+}
+
+type EventsRepository interface {
+	Create(ctx context.Context, params dto.CreateEventParams) error
+	All(ctx context.Context, missionID int) ([]*models.Event, error)
 }
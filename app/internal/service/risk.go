@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/apperrors"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+)
+
+var riskLevelRanks = map[models.RiskLevel]int{
+	models.RiskLevelLow:    0,
+	models.RiskLevelMedium: 1,
+	models.RiskLevelHigh:   2,
+}
+
+// missionRiskLevel is the highest risk level among the targets, low if there are none
+func missionRiskLevel(targets []*models.Target) models.RiskLevel {
+	risk := models.RiskLevelLow
+	for _, t := range targets {
+		if riskLevelRanks[t.RiskLevel] > riskLevelRanks[risk] {
+			risk = t.RiskLevel
+		}
+	}
+
+	return risk
+}
+
+// minExperienceYears returns experience required from a cat to take a mission with the given risk level
+func (s Service) minExperienceYears(risk models.RiskLevel) int {
+	if risk != models.RiskLevelHigh {
+		return 0
+	}
+
+	return s.rules.HighRiskMinExperienceYears
+}
+
+func (s Service) checkCatExperience(cat *models.Cat, risk models.RiskLevel) error {
+	minExperience := s.minExperienceYears(risk)
+	if int(cat.ExperienceYears) < minExperience {
+		return apperrors.CatNotExperienced(cat.ID, minExperience)
+	}
+
+	return nil
+}
+
+// checkAssignedCatExperience fails if the cat assigned to the mission can't handle targets of the given risk level
+func (s Service) checkAssignedCatExperience(ctx context.Context, mission *models.Mission, risk models.RiskLevel) error {
+	if mission.AssignedCatID == nil || s.minExperienceYears(risk) == 0 {
+		return nil
+	}
+
+	cat, err := s.catsRepository.One(ctx, *mission.AssignedCatID)
+	if err != nil {
+		return fmt.Errorf("get cat %d: %w", *mission.AssignedCatID, err)
+	}
+
+	return s.checkCatExperience(cat, risk)
+}
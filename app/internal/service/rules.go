@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/config"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/apperrors"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/service/dto"
+)
+
+func (s Service) GetRules() config.Rules {
+	return s.rules
+}
+
+// checkCatWorkload fails if the cat can't take the mission: either it is double-booked
+// or it has too many open missions during the mission's schedule. The mission itself is not counted.
+// The cat is locked till the end of the transaction, so concurrent assignments of it are checked one by one.
+func (s Service) checkCatWorkload(ctx context.Context, catID int, mission *models.Mission) error {
+	err := s.catsRepository.Lock(ctx, catID)
+	if err != nil {
+		return fmt.Errorf("lock cat %d: %w", catID, err)
+	}
+
+	missions, err := s.missionsRepository.All(ctx, dto.GetMissionsParams{CatID: &catID})
+	if err != nil {
+		return fmt.Errorf("missions repository: all: %w", err)
+	}
+
+	_, err = catWorkload(catID, missions, mission, s.rules.MaxOpenMissionsPerCat)
+
+	return err
+}
+
+// catWorkload counts open missions of the cat overlapping the mission's schedule, the mission itself is not counted.
+// It fails if the cat is double-booked or has maxOpenMissions such missions already, zero means unlimited.
+func catWorkload(catID int, catMissions []*models.Mission, mission *models.Mission, maxOpenMissions int) (openMissions int, err error) {
+	for _, m := range catMissions {
+		if m.IsCompleted || m.ID == mission.ID || !schedulesOverlap(m, mission) {
+			continue
+		}
+
+		if isScheduled(m) && isScheduled(mission) {
+			return openMissions, apperrors.CatDoubleBooked(catID, m.ID)
+		}
+
+		openMissions++
+	}
+
+	if maxOpenMissions > 0 && openMissions >= maxOpenMissions {
+		return openMissions, apperrors.CatIsBusy(catID, maxOpenMissions)
+	}
+
+	return openMissions, nil
+}
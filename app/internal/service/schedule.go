@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/service/dto"
+)
+
+// GetCatSchedule returns open missions of the cat which are in progress or planned for the future.
+func (s Service) GetCatSchedule(ctx context.Context, catID int) (out *models.CatSchedule, err error) {
+	out = &models.CatSchedule{
+		Current:  []*models.MissionFull{},
+		Upcoming: []*models.MissionFull{},
+	}
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err = s.catsRepository.One(ctx, catID)
+		if err != nil {
+			return fmt.Errorf("get cat %d: %w", catID, err)
+		}
+
+		missions, err := s.missionsRepository.All(ctx, dto.GetMissionsParams{CatID: &catID})
+		if err != nil {
+			return fmt.Errorf("missions repository: all: %w", err)
+		}
+
+		sort.SliceStable(missions, func(i, j int) bool {
+			return startsBefore(missions[i], missions[j])
+		})
+
+		now := time.Now()
+		scheduled := make([]*models.MissionFull, 0, len(missions))
+
+		for _, m := range missions {
+			if m.IsCompleted || (m.EndsAt != nil && m.EndsAt.Before(now)) {
+				continue
+			}
+
+			mission := &models.MissionFull{Mission: m}
+			scheduled = append(scheduled, mission)
+
+			if m.StartsAt != nil && m.StartsAt.After(now) {
+				out.Upcoming = append(out.Upcoming, mission)
+			} else {
+				out.Current = append(out.Current, mission)
+			}
+		}
+
+		err = s.loadMissionsRelations(ctx, scheduled, dto.MissionExpand{})
+		if err != nil {
+			return fmt.Errorf("load relations: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("within transaction: %w", err)
+	}
+
+	return out, nil
+}
+
+// isScheduled reports whether the mission has both planned start and end
+func isScheduled(m *models.Mission) bool {
+	return m.StartsAt != nil && m.EndsAt != nil
+}
+
+// schedulesOverlap treats missing start or end as an unbounded window
+func schedulesOverlap(a, b *models.Mission) bool {
+	aStartsBeforeBEnds := a.StartsAt == nil || b.EndsAt == nil || a.StartsAt.Before(*b.EndsAt)
+	bStartsBeforeAEnds := b.StartsAt == nil || a.EndsAt == nil || b.StartsAt.Before(*a.EndsAt)
+
+	return aStartsBeforeBEnds && bStartsBeforeAEnds
+}
+
+// startsBefore orders missions by planned start, unscheduled missions go first
+func startsBefore(a, b *models.Mission) bool {
+	switch {
+	case a.StartsAt == nil:
+		return b.StartsAt != nil
+	case b.StartsAt == nil:
+		return false
+	default:
+		return a.StartsAt.Before(*b.StartsAt)
+	}
+}
@@ -2,25 +2,40 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/illiafox/spy-cat-test-assignment/app/config"
 	"github.com/illiafox/spy-cat-test-assignment/app/internal/apperrors"
 	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
 	"github.com/illiafox/spy-cat-test-assignment/app/internal/service/dto"
 )
 
 type Service struct {
+	rules       config.Rules
+	notes       config.Notes
+	attachments config.Attachments
+
 	catBreedChecker    CatBreedChecker
+	countryDirectory   CountryDirectory
 	catsRepository     CatsRepository
 	missionsRepository MissionsRepository
 	targetsRepository  TargetsRepository
 	notesRepository    NotesRepository
+	eventsRepository   EventsRepository
+	personsRepository  PersonsRepository
+
+	noteRevisionsRepository   NoteRevisionsRepository
+	noteAttachmentsRepository NoteAttachmentsRepository
+	blobStore                 BlobStore
+
+	assignmentsRepository AssignmentsRepository
 
 	transactor Transactor
 }
 
-func NewService(catBreedChecker CatBreedChecker, catsRepository CatsRepository, missionsRepository MissionsRepository, targetsRepository TargetsRepository, notesRepository NotesRepository, transactor Transactor) *Service {
-	return &Service{catBreedChecker: catBreedChecker, catsRepository: catsRepository, missionsRepository: missionsRepository, targetsRepository: targetsRepository, notesRepository: notesRepository, transactor: transactor}
+func NewService(rules config.Rules, notes config.Notes, attachments config.Attachments, catBreedChecker CatBreedChecker, countryDirectory CountryDirectory, catsRepository CatsRepository, missionsRepository MissionsRepository, targetsRepository TargetsRepository, notesRepository NotesRepository, noteRevisionsRepository NoteRevisionsRepository, noteAttachmentsRepository NoteAttachmentsRepository, blobStore BlobStore, eventsRepository EventsRepository, personsRepository PersonsRepository, assignmentsRepository AssignmentsRepository, transactor Transactor) *Service {
+	return &Service{rules: rules, notes: notes, attachments: attachments, catBreedChecker: catBreedChecker, countryDirectory: countryDirectory, catsRepository: catsRepository, missionsRepository: missionsRepository, targetsRepository: targetsRepository, notesRepository: notesRepository, noteRevisionsRepository: noteRevisionsRepository, noteAttachmentsRepository: noteAttachmentsRepository, blobStore: blobStore, eventsRepository: eventsRepository, personsRepository: personsRepository, assignmentsRepository: assignmentsRepository, transactor: transactor}
 }
 
 func (s Service) AddCat(ctx context.Context, params dto.CreateCatParams) (catID int, err error) {
@@ -60,10 +75,37 @@ func (s Service) UpdateCatByID(ctx context.Context, params dto.UpdateCatParams)
 	return nil
 }
 
+// DeleteCatByID unassigns the cat from its missions, assignments history is kept
 func (s Service) DeleteCatByID(ctx context.Context, catID int) error {
-	err := s.catsRepository.Delete(ctx, catID)
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		missionIDs, err := s.assignmentsRepository.CloseByCat(ctx, catID)
+		if err != nil {
+			return fmt.Errorf("close assignments of cat %d: %w", catID, err)
+		}
+
+		err = s.missionsRepository.UnassignCat(ctx, catID)
+		if err != nil {
+			return fmt.Errorf("unassign cat %d from missions: %w", catID, err)
+		}
+
+		err = s.catsRepository.Delete(ctx, catID)
+		if err != nil {
+			return fmt.Errorf("cats repository: delete: %w", err)
+		}
+
+		for _, missionID := range missionIDs {
+			err = s.recordEvent(ctx, missionID, models.EventCatUnassigned, map[string]any{
+				"previous_cat_id": catID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("cats repository: delete: %w", err)
+		return fmt.Errorf("within transaction: %w", err)
 	}
 
 	return nil
@@ -71,44 +113,91 @@ func (s Service) DeleteCatByID(ctx context.Context, catID int) error {
 
 // Missions
 
-func (s Service) GetMissions(ctx context.Context, params dto.GetMissionsParams) ([]*models.Mission, error) {
-	missions, err := s.missionsRepository.All(ctx, params)
+func (s Service) GetMissions(ctx context.Context, params dto.GetMissionsParams) (out []*models.MissionFull, err error) {
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		missions, err := s.missionsRepository.All(ctx, params)
+		if err != nil {
+			return fmt.Errorf("missions repository: all: %w", err)
+		}
+
+		out = make([]*models.MissionFull, len(missions))
+		for i := range missions {
+			out[i] = &models.MissionFull{Mission: missions[i]}
+		}
+
+		err = s.loadMissionsRelations(ctx, out, params.Expand)
+		if err != nil {
+			return fmt.Errorf("load relations: %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("missions repository: all: %w", err)
+		return nil, fmt.Errorf("within transaction: %w", err)
 	}
 
-	return missions, nil
+	return out, nil
 }
 
-func (s Service) CreateMission(ctx context.Context, params dto.CreateMissionParams) (missionID int, err error) {
-	if targetsCount := len(params.Targets); targetsCount == 0 || targetsCount > 3 { // minimum: 1, maximum: 3
-		return -1, apperrors.InvalidTargetsCount(targetsCount, 1, 3)
+// CreateMission also returns targets of other missions similar to the new ones, they might be the same persons
+func (s Service) CreateMission(ctx context.Context, params dto.CreateMissionParams) (missionID int, similar []*models.SimilarTarget, err error) {
+	if targetsCount := len(params.Targets); !s.rules.TargetsCountAllowed(targetsCount) {
+		return -1, nil, apperrors.InvalidTargetsCount(targetsCount, s.rules.MinTargets, s.rules.MaxTargets)
+	}
+
+	if params.StartsAt != nil && params.EndsAt != nil && !params.EndsAt.After(*params.StartsAt) {
+		return -1, nil, apperrors.InvalidMissionSchedule()
+	}
+
+	if err = checkDuplicateTargets(nil, params.Targets); err != nil {
+		return -1, nil, err
 	}
 
 	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
-		missionID, err = s.missionsRepository.Create(ctx)
+		err = s.checkTargetsPersons(ctx, params.Targets)
+		if err != nil {
+			return fmt.Errorf("check targets persons: %w", err)
+		}
+
+		missionID, err = s.missionsRepository.Create(ctx, params)
 		if err != nil {
 			return fmt.Errorf("create mission: %w", err)
 		}
 
-		const lastTargetID = 0
-		err = s.targetsRepository.Create(ctx, missionID, lastTargetID, params.Targets)
+		err = s.targetsRepository.Create(ctx, missionID, params.Targets)
 		if err != nil {
 			return fmt.Errorf("create targets for mission %d: %w", missionID, err)
 		}
 
-		return nil
+		similar, err = s.targetsRepository.Similar(ctx, missionID, params.Targets)
+		if err != nil {
+			return fmt.Errorf("targets repository: similar: %w", err)
+		}
+
+		return s.recordEvent(ctx, missionID, models.EventMissionCreated, map[string]any{
+			"budget":    params.Budget,
+			"starts_at": params.StartsAt,
+			"ends_at":   params.EndsAt,
+			"targets":   targetsPayload(params.Targets),
+		})
 	})
 	if err != nil {
-		return -1, fmt.Errorf("within transaction: %w", err)
+		return -1, nil, fmt.Errorf("within transaction: %w", err)
 	}
 
-	return missionID, nil
+	return missionID, similar, nil
 }
 
-func (s Service) AddMissionTargets(ctx context.Context, missionID int, newTargets []dto.CreateTargetParams) (err error) {
+// AddMissionTargets also returns targets of other missions similar to the new ones, they might be the same persons
+func (s Service) AddMissionTargets(ctx context.Context, missionID int, newTargets []dto.CreateTargetParams) (similar []*models.SimilarTarget, err error) {
 	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
-		mission, err := s.GetMissionByID(ctx, missionID)
+		// concurrent requests must see targets added by each other to respect the limit
+		err := s.missionsRepository.Lock(ctx, missionID)
+		if err != nil {
+			return fmt.Errorf("lock mission %d: %w", missionID, err)
+		}
+
+		mission, err := s.GetMissionByID(ctx, missionID, dto.MissionExpand{})
 		if err != nil {
 			return fmt.Errorf("get mission %d: %w", missionID, err)
 		}
@@ -118,27 +207,58 @@ func (s Service) AddMissionTargets(ctx context.Context, missionID int, newTarget
 		}
 
 		targetsCount := len(mission.Targets) + len(newTargets)
-		if targetsCount == 0 || targetsCount > 3 { // minimum: 1, maximum: 3
-			return apperrors.InvalidTargetsCount(targetsCount, 1, 3).
+		if !s.rules.TargetsCountAllowed(targetsCount) {
+			return apperrors.InvalidTargetsCount(targetsCount, s.rules.MinTargets, s.rules.MaxTargets).
 				Wrap("too many targets")
 		}
 
-		err = s.targetsRepository.Create(ctx, missionID, len(mission.Targets), newTargets)
+		existing := make([]*models.Target, len(mission.Targets))
+		for i := range mission.Targets {
+			existing[i] = mission.Targets[i].Target
+		}
+
+		err = checkDuplicateTargets(existing, newTargets)
+		if err != nil {
+			return err
+		}
+
+		for _, t := range newTargets {
+			err = s.checkAssignedCatExperience(ctx, mission.Mission, t.RiskLevel)
+			if err != nil {
+				return fmt.Errorf("check assigned cat experience: %w", err)
+			}
+		}
+
+		err = s.checkTargetsPersons(ctx, newTargets)
+		if err != nil {
+			return fmt.Errorf("check targets persons: %w", err)
+		}
+
+		err = s.targetsRepository.Create(ctx, missionID, newTargets)
 		if err != nil {
 			return fmt.Errorf("create targets for mission %d: %w", missionID, err)
 		}
 
-		return nil
+		similar, err = s.targetsRepository.Similar(ctx, missionID, newTargets)
+		if err != nil {
+			return fmt.Errorf("targets repository: similar: %w", err)
+		}
+
+		return s.recordEvent(ctx, missionID, models.EventTargetsAdded, map[string]any{
+			"targets": targetsPayload(newTargets),
+		})
 	})
 	if err != nil {
-		return fmt.Errorf("within transaction: %w", err)
+		return nil, fmt.Errorf("within transaction: %w", err)
 	}
 
-	return nil
+	return similar, nil
 }
 
-func (s Service) GetMissionByID(ctx context.Context, missionID int) (out *models.MissionFull, err error) {
+// GetMissionByID returns the mission with its targets, other relations are loaded only if requested.
+func (s Service) GetMissionByID(ctx context.Context, missionID int, expand dto.MissionExpand) (out *models.MissionFull, err error) {
 	out = new(models.MissionFull)
+	expand.Targets = true
 
 	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
 		out.Mission, err = s.missionsRepository.One(ctx, missionID)
@@ -146,9 +266,9 @@ func (s Service) GetMissionByID(ctx context.Context, missionID int) (out *models
 			return fmt.Errorf("get mission by id: %w", err)
 		}
 
-		out.Targets, err = s.targetsRepository.All(ctx, missionID)
+		err = s.loadMissionsRelations(ctx, []*models.MissionFull{out}, expand)
 		if err != nil {
-			return fmt.Errorf("get targets by mission id %d: %w", missionID, err)
+			return fmt.Errorf("load relations of mission %d: %w", missionID, err)
 		}
 
 		return nil
@@ -184,19 +304,107 @@ func (s Service) UpdateMissionByID(ctx context.Context, params dto.UpdateMission
 			}
 		}
 
+		// mission as it will be after the update, used to validate schedule and workload
+		updated := *mission
+		if params.StartsAt != nil {
+			updated.StartsAt = params.StartsAt
+		}
+		if params.EndsAt != nil {
+			updated.EndsAt = params.EndsAt
+		}
+
+		if updated.StartsAt != nil && updated.EndsAt != nil && !updated.EndsAt.After(*updated.StartsAt) {
+			return apperrors.InvalidMissionSchedule()
+		}
+
 		if params.AssignedCatID != nil {
-			_, err := s.catsRepository.One(ctx, *params.AssignedCatID)
+			cat, err := s.catsRepository.One(ctx, *params.AssignedCatID)
 			if err != nil {
 				return fmt.Errorf("get cat: %w", err)
 			}
+
+			targets, err := s.targetsRepository.All(ctx, params.MissionID)
+			if err != nil {
+				return fmt.Errorf("get targets: %w", err)
+			}
+
+			err = s.checkCatExperience(cat, missionRiskLevel(targets))
+			if err != nil {
+				return fmt.Errorf("check cat experience: %w", err)
+			}
+
+			updated.AssignedCatID = params.AssignedCatID
+		}
+
+		scheduleChanged := params.StartsAt != nil || params.EndsAt != nil
+		if updated.AssignedCatID != nil && !params.Unassign && (params.AssignedCatID != nil || scheduleChanged) {
+			err = s.checkCatWorkload(ctx, *updated.AssignedCatID, &updated)
+			if err != nil {
+				return fmt.Errorf("check cat workload: %w", err)
+			}
 		}
 
 		err = s.missionsRepository.Update(ctx, params)
 		if err != nil {
-			fmt.Println(err)
 			return fmt.Errorf("update mission %d: %w", params.MissionID, err)
 		}
 
+		catChanged := params.AssignedCatID != nil &&
+			(mission.AssignedCatID == nil || *mission.AssignedCatID != *params.AssignedCatID)
+
+		if catChanged {
+			err = s.recordAssignment(ctx, mission, params.AssignedCatID)
+			if err != nil {
+				return fmt.Errorf("record assignment: %w", err)
+			}
+
+			err = s.recordEvent(ctx, params.MissionID, models.EventCatAssigned, map[string]any{
+				"previous_cat_id": mission.AssignedCatID,
+				"cat_id":          *params.AssignedCatID,
+			})
+			if err != nil {
+				return err
+			}
+		} else if params.Unassign && mission.AssignedCatID != nil {
+			err = s.recordAssignment(ctx, mission, nil)
+			if err != nil {
+				return fmt.Errorf("record unassignment: %w", err)
+			}
+
+			err = s.recordEvent(ctx, params.MissionID, models.EventCatUnassigned, map[string]any{
+				"previous_cat_id": mission.AssignedCatID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if params.Budget != nil || scheduleChanged {
+			payload := make(map[string]any)
+			if params.Budget != nil {
+				payload["previous_budget"] = mission.Budget
+				payload["budget"] = *params.Budget
+			}
+			if scheduleChanged {
+				payload["previous_starts_at"] = mission.StartsAt
+				payload["previous_ends_at"] = mission.EndsAt
+				payload["starts_at"] = updated.StartsAt
+				payload["ends_at"] = updated.EndsAt
+			}
+
+			err = s.recordEvent(ctx, params.MissionID, models.EventMissionUpdated, payload)
+			if err != nil {
+				return err
+			}
+		}
+
+		if params.IsCompleted != nil {
+			err = s.recordEvent(ctx, params.MissionID, models.EventMissionCompleted, nil)
+			if err != nil {
+				return err
+			}
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -207,6 +415,8 @@ func (s Service) UpdateMissionByID(ctx context.Context, params dto.UpdateMission
 }
 
 func (s Service) DeleteMissionByID(ctx context.Context, missionID int) (err error) {
+	var attachments []*models.NoteAttachment
+
 	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
 		mission, err := s.missionsRepository.One(ctx, missionID)
 		if err != nil {
@@ -221,6 +431,12 @@ func (s Service) DeleteMissionByID(ctx context.Context, missionID int) (err erro
 			return apperrors.MissionAlreadyCompleted(missionID)
 		}
 
+		attachments, err = s.noteAttachmentsRepository.AllByMission(ctx, missionID)
+		if err != nil {
+			return fmt.Errorf("get attachments of mission %d: %w", missionID, err)
+		}
+
+		// attachments are deleted along with the mission
 		err = s.missionsRepository.Delete(ctx, missionID)
 		if err != nil {
 			return fmt.Errorf("delete mission %d: %w", missionID, err)
@@ -232,32 +448,28 @@ func (s Service) DeleteMissionByID(ctx context.Context, missionID int) (err erro
 		return fmt.Errorf("within transaction: %w", err)
 	}
 
+	s.deleteBlobs(ctx, attachments...)
+
 	return nil
 }
 
 // Targets
 
-func (s Service) GetTargetsByMissionID(ctx context.Context, missionID int) (out []*models.TargetFull, err error) {
+func (s Service) GetTargetsByMissionID(ctx context.Context, missionID int, filter dto.NotesFilter) (out []*models.TargetFull, err error) {
 	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
 		targets, err := s.targetsRepository.All(ctx, missionID)
 		if err != nil {
 			return fmt.Errorf("targets repo: all: %w", err)
 		}
 
-		out = make([]*models.TargetFull, len(targets))
+		s.fillTargetsCountries(targets...)
 
+		out = make([]*models.TargetFull, len(targets))
 		for i := range targets {
-			out[i] = &models.TargetFull{
-				Target: targets[i],
-			}
-
-			out[i].Notes, err = s.notesRepository.All(ctx, missionID, targets[i].ID)
-			if err != nil {
-				return fmt.Errorf("notes repo: get notes for target %d: %w", targets[i].ID, err)
-			}
+			out[i] = &models.TargetFull{Target: targets[i]}
 		}
 
-		return nil
+		return s.embedNotes(ctx, out, filter)
 	})
 	if err != nil {
 		return nil, fmt.Errorf("within transaction: %w", err)
@@ -266,7 +478,20 @@ func (s Service) GetTargetsByMissionID(ctx context.Context, missionID int) (out
 	return out, nil
 }
 
-func (s Service) GetTargetByID(ctx context.Context, missionID, targetID int) (out *models.TargetFull, err error) {
+func (s Service) GetNearbyTargets(ctx context.Context, params dto.NearbyTargetsParams) ([]*models.NearbyTarget, error) {
+	targets, err := s.targetsRepository.Nearby(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("targets repository: nearby: %w", err)
+	}
+
+	for _, target := range targets {
+		s.fillTargetsCountries(target.Target)
+	}
+
+	return targets, nil
+}
+
+func (s Service) GetTargetByID(ctx context.Context, missionID, targetID int, filter dto.NotesFilter) (out *models.TargetFull, err error) {
 	out = new(models.TargetFull)
 
 	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
@@ -284,12 +509,9 @@ func (s Service) GetTargetByID(ctx context.Context, missionID, targetID int) (ou
 			return apperrors.TargetNotFound(targetID).Wrap("mission")
 		}
 
-		out.Notes, err = s.notesRepository.All(ctx, missionID, targetID)
-		if err != nil {
-			return fmt.Errorf("get notes by target id %d: %w", targetID, err)
-		}
+		s.fillTargetsCountries(out.Target)
 
-		return nil
+		return s.embedNotes(ctx, []*models.TargetFull{out}, filter)
 	})
 	if err != nil {
 		return nil, fmt.Errorf("within transaction: %w", err)
@@ -298,9 +520,70 @@ func (s Service) GetTargetByID(ctx context.Context, missionID, targetID int) (ou
 	return out, nil
 }
 
-func (s Service) CompleteTargetByID(ctx context.Context, missionID, targetID int) (err error) {
+func (s Service) UpdateTargetByID(ctx context.Context, params dto.UpdateTargetParams) (err error) {
 	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
-		_, err = s.missionsRepository.One(ctx, missionID)
+		mission, err := s.missionsRepository.One(ctx, params.MissionID)
+		if err != nil {
+			return fmt.Errorf("get mission %d: %w", params.MissionID, err)
+		}
+
+		if mission.IsCompleted {
+			return apperrors.MissionAlreadyCompleted(params.MissionID).Wrap("can't update target")
+		}
+
+		target, err := s.targetsRepository.One(ctx, params.MissionID, params.TargetID)
+		if err != nil {
+			return fmt.Errorf("get target by id: %w", err)
+		}
+
+		if target.IsCompleted {
+			return apperrors.TargetAlreadyCompleted(params.TargetID).Wrap("can't update")
+		}
+
+		if params.Name != nil || params.Country != nil {
+			err = s.checkRenamedTargetDuplicates(ctx, target, params)
+			if err != nil {
+				return err
+			}
+		}
+
+		if params.RiskLevel != nil {
+			err = s.checkAssignedCatExperience(ctx, mission, *params.RiskLevel)
+			if err != nil {
+				return fmt.Errorf("check assigned cat experience: %w", err)
+			}
+		}
+
+		err = s.targetsRepository.Update(ctx, params)
+		if err != nil {
+			return fmt.Errorf("update target %d: %w", params.TargetID, err)
+		}
+
+		return s.recordEvent(ctx, params.MissionID, models.EventTargetUpdated, map[string]any{
+			"target_id":        params.TargetID,
+			"previous_name":    target.Name,
+			"previous_country": target.Country,
+			"name":             params.Name,
+			"country":          params.Country,
+			"city":             params.City,
+			"risk_level":       params.RiskLevel,
+			"priority":         params.Priority,
+			"latitude":         params.Latitude,
+			"longitude":        params.Longitude,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("within transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (s Service) CompleteTargetByID(ctx context.Context, params dto.CompleteTargetParams) (err error) {
+	missionID, targetID := params.MissionID, params.TargetID
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		mission, err := s.missionsRepository.One(ctx, missionID)
 		if err != nil {
 			return fmt.Errorf("get mission %d: %w", missionID, err)
 		}
@@ -314,14 +597,25 @@ func (s Service) CompleteTargetByID(ctx context.Context, missionID, targetID int
 			return apperrors.TargetAlreadyCompleted(targetID)
 		}
 
+		err = s.checkCompletionPolicy(ctx, mission, params)
+		if err != nil {
+			return fmt.Errorf("check completion policy: %w", err)
+		}
+
 		isCompleted := true
 
-		err = s.targetsRepository.Update(ctx, missionID, targetID, &isCompleted)
+		err = s.targetsRepository.Update(ctx, dto.UpdateTargetParams{
+			MissionID:   missionID,
+			TargetID:    targetID,
+			IsCompleted: &isCompleted,
+		})
 		if err != nil {
 			return fmt.Errorf("update target %d: %w", targetID, err)
 		}
 
-		return nil
+		return s.recordEvent(ctx, missionID, models.EventTargetCompleted, map[string]any{
+			"target_id": targetID,
+		})
 	})
 	if err != nil {
 		return fmt.Errorf("within transaction: %w", err)
@@ -331,6 +625,8 @@ func (s Service) CompleteTargetByID(ctx context.Context, missionID, targetID int
 }
 
 func (s Service) DeleteTargetByID(ctx context.Context, missionID, targetID int) (err error) {
+	var attachments []*models.NoteAttachment
+
 	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
 		_, err = s.missionsRepository.One(ctx, missionID)
 		if err != nil {
@@ -346,48 +642,89 @@ func (s Service) DeleteTargetByID(ctx context.Context, missionID, targetID int)
 			return apperrors.TargetAlreadyCompleted(targetID).Wrap("can't delete")
 		}
 
+		attachments, err = s.noteAttachmentsRepository.AllByTarget(ctx, missionID, targetID)
+		if err != nil {
+			return fmt.Errorf("get attachments of target %d: %w", targetID, err)
+		}
+
+		// attachments are deleted along with the target
 		err = s.targetsRepository.Delete(ctx, missionID, targetID)
 		if err != nil {
 			return fmt.Errorf("delete target %d: %w", targetID, err)
 		}
 
-		return nil
+		return s.recordEvent(ctx, missionID, models.EventTargetDeleted, map[string]any{
+			"target_id": targetID,
+			"name":      target.Name,
+			"country":   target.Country,
+		})
 	})
 	if err != nil {
 		return fmt.Errorf("within transaction: %w", err)
 	}
 
+	s.deleteBlobs(ctx, attachments...)
+
 	return nil
 }
 
-func (s Service) AddTargetNote(ctx context.Context, missionID, targetID int, contents []string) (err error) {
-	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
-		mission, err := s.missionsRepository.One(ctx, missionID)
+// AddTargetNote adds notes authored by the cat, which must be assigned to the mission.
+// Attachments are added along with a single note only.
+func (s Service) AddTargetNote(ctx context.Context, params dto.CreateNotesParams) (err error) {
+	if len(params.Attachments) > 0 && len(params.Contents) != 1 {
+		return apperrors.InvalidRequest(errors.New("attachments can be added along with a single note only"))
+	}
+
+	uploads := make([]*attachmentUpload, len(params.Attachments))
+	for i, file := range params.Attachments {
+		uploads[i], err = s.prepareAttachment(file)
 		if err != nil {
-			return fmt.Errorf("get mission %d: %w", missionID, err)
+			return fmt.Errorf("attachment %d: %w", i, err)
 		}
+	}
 
-		if mission.IsCompleted {
-			return apperrors.MissionAlreadyCompleted(targetID)
+	var stored []*models.NoteAttachment
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		mission, err := s.checkNotesWritable(ctx, params.MissionID, params.TargetID)
+		if err != nil {
+			return err
 		}
 
-		target, err := s.targetsRepository.One(ctx, missionID, targetID)
+		err = checkNoteAuthor(mission, params.AuthorCatID)
 		if err != nil {
-			return fmt.Errorf("get target by id: %w", err)
+			return err
 		}
 
-		if target.IsCompleted {
-			return apperrors.TargetAlreadyCompleted(targetID)
+		noteIDs, err := s.createNotes(ctx, params)
+		if err != nil {
+			return err
 		}
 
-		err = s.notesRepository.Create(ctx, missionID, targetID, contents)
+		err = s.recordEvent(ctx, params.MissionID, models.EventNotesAdded, map[string]any{
+			"target_id":     params.TargetID,
+			"author_cat_id": *params.AuthorCatID,
+			"count":         len(params.Contents),
+		})
 		if err != nil {
-			return fmt.Errorf("add notes by target id %d: %w", targetID, err)
+			return err
+		}
+
+		for _, upload := range uploads {
+			attachment, err := s.storeAttachment(ctx, params.MissionID, params.TargetID, noteIDs[0], upload)
+			if attachment != nil {
+				stored = append(stored, attachment)
+			}
+			if err != nil {
+				return err
+			}
 		}
 
 		return nil
 	})
 	if err != nil {
+		s.deleteBlobs(ctx, stored...)
+
 		return fmt.Errorf("within transaction: %w", err)
 	}
 
@@ -0,0 +1,20 @@
+package http
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/illiafox/spy-cat-test-assignment/app/pkg/actor"
+)
+
+// ActorHeader identifies who performs the request, it is recorded in mission timeline
+const ActorHeader = "X-Actor"
+
+func ActorMiddleware() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		name := strings.Clone(strings.TrimSpace(ctx.Get(ActorHeader)))
+		ctx.SetUserContext(actor.Inject(ctx.UserContext(), name))
+
+		return ctx.Next()
+	}
+}
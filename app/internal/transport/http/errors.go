@@ -18,10 +18,27 @@ var errorCodesToHTTP = map[codes.Code]int{
 	codes.CatNotFound:               http.StatusNotFound,
 	codes.MissionNotFound:           http.StatusNotFound,
 	codes.TargetNotFound:            http.StatusNotFound,
+	codes.PersonNotFound:            http.StatusNotFound,
+	codes.NoteNotFound:              http.StatusNotFound,
+	codes.NoteRevisionNotFound:      http.StatusNotFound,
+	codes.NoteAttachmentNotFound:    http.StatusNotFound,
+	codes.AttachmentTooLarge:        http.StatusRequestEntityTooLarge,
+	codes.UnsupportedAttachmentType: http.StatusUnsupportedMediaType,
 	codes.MissionAlreadyCompleted:   http.StatusForbidden,
 	codes.CatAlreadyAssigned:        http.StatusForbidden,
 	codes.AllTargetsAreNotCompleted: http.StatusForbidden,
 	codes.TargetAlreadyCompleted:    http.StatusForbidden,
+	codes.NoCandidatesAvailable:     http.StatusNotFound,
+	codes.MissionNotCompleted:       http.StatusForbidden,
+	codes.TargetNotCompleted:        http.StatusForbidden,
+	codes.PermissionDenied:          http.StatusForbidden,
+	codes.CatIsBusy:                 http.StatusForbidden,
+	codes.CatDoubleBooked:           http.StatusConflict,
+	codes.CatNotExperienced:         http.StatusForbidden,
+	codes.NoteAuthorNotAssigned:     http.StatusForbidden,
+	codes.DuplicateTarget:           http.StatusConflict,
+	codes.CompletionPolicyNotMet:    http.StatusForbidden,
+	codes.SearchUnavailable:         http.StatusNotImplemented,
 }
 
 type Error struct {
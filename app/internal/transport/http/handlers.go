@@ -3,6 +3,7 @@ package http
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/illiafox/spy-cat-test-assignment/app/internal/apperrors"
@@ -13,8 +14,16 @@ type Handler struct {
 	service Service
 }
 
+func (h Handler) GetRules(ctx *fiber.Ctx) error {
+	var resp GetRulesResponse
+	resp.Ok = true
+	resp.Rules = RulesFromConfig(h.service.GetRules())
+
+	return ctx.JSON(resp)
+}
+
 func (h Handler) GetCats(ctx *fiber.Ctx) error {
-	cats, err := h.service.GetCats(ctx.Context())
+	cats, err := h.service.GetCats(ctx.UserContext())
 	if err != nil {
 		return RespondWithError(ctx, fmt.Errorf("failed to get cats: %w", err))
 	}
@@ -41,7 +50,7 @@ func (h Handler) CreateCat(ctx *fiber.Ctx) error {
 		return RespondWithError(ctx, apperrors.InvalidRequest(err))
 	}
 
-	catID, err := h.service.AddCat(ctx.Context(), dto.CreateCatParams(req))
+	catID, err := h.service.AddCat(ctx.UserContext(), dto.CreateCatParams(req))
 	if err != nil {
 		return RespondWithError(ctx, fmt.Errorf("failed to add cat: %w", err))
 	}
@@ -59,7 +68,7 @@ func (h Handler) GetCatByID(ctx *fiber.Ctx) error {
 		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse cat id"))
 	}
 
-	cat, err := h.service.GetCatByID(ctx.Context(), catID)
+	cat, err := h.service.GetCatByID(ctx.UserContext(), catID)
 	if err != nil {
 		return RespondWithError(ctx, fmt.Errorf("failed to get cat: %w", err))
 	}
@@ -88,7 +97,7 @@ func (h Handler) UpdateCatByID(ctx *fiber.Ctx) error {
 		return RespondWithError(ctx, apperrors.InvalidRequest(err))
 	}
 
-	err = h.service.UpdateCatByID(ctx.Context(), dto.UpdateCatParams(req))
+	err = h.service.UpdateCatByID(ctx.UserContext(), dto.UpdateCatParams(req))
 	if err != nil {
 		return RespondWithError(ctx, fmt.Errorf("failed to update cat: %w", err))
 	}
@@ -105,7 +114,7 @@ func (h Handler) DeleteCatByID(ctx *fiber.Ctx) error {
 		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse cat id"))
 	}
 
-	err = h.service.DeleteCatByID(ctx.Context(), catID)
+	err = h.service.DeleteCatByID(ctx.UserContext(), catID)
 	if err != nil {
 		return RespondWithError(ctx, fmt.Errorf("failed to delete cat: %w", err))
 	}
@@ -116,6 +125,47 @@ func (h Handler) DeleteCatByID(ctx *fiber.Ctx) error {
 	return ctx.JSON(resp)
 }
 
+func (h Handler) GetCatAssignments(ctx *fiber.Ctx) error {
+	catID, err := ctx.ParamsInt("cat_id")
+	if err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse cat id"))
+	}
+
+	assignments, err := h.service.GetCatAssignments(ctx.UserContext(), catID)
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to get cat assignments: %w", err))
+	}
+
+	out := make([]Assignment, len(assignments))
+	for i := range assignments {
+		out[i] = AssignmentFromModel(assignments[i])
+	}
+
+	var resp GetAssignmentsResponse
+	resp.Ok = true
+	resp.Assignments = out
+
+	return ctx.JSON(resp)
+}
+
+func (h Handler) GetCatSchedule(ctx *fiber.Ctx) error {
+	catID, err := ctx.ParamsInt("cat_id")
+	if err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse cat id"))
+	}
+
+	schedule, err := h.service.GetCatSchedule(ctx.UserContext(), catID)
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to get cat schedule: %w", err))
+	}
+
+	var resp GetCatScheduleResponse
+	resp.Ok = true
+	resp.Schedule = CatScheduleFromModel(schedule)
+
+	return ctx.JSON(resp)
+}
+
 // Missions
 
 func (h Handler) extractMissionID(ctx *fiber.Ctx) (int, error) {
@@ -133,14 +183,19 @@ func (h Handler) GetMissions(ctx *fiber.Ctx) error {
 		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse query"))
 	}
 
-	missions, err := h.service.GetMissions(ctx.Context(), dto.GetMissionsParams(req))
+	params, err := req.Params()
+	if err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err))
+	}
+
+	missions, err := h.service.GetMissions(ctx.UserContext(), params)
 	if err != nil {
 		return RespondWithError(ctx, fmt.Errorf("failed to get missions: %w", err))
 	}
 
-	out := make([]Mission, len(missions))
+	out := make([]MissionFull, len(missions))
 	for i := range missions {
-		out[i] = MissionFromModel(missions[i])
+		out[i] = MissionFullFromModel(missions[i])
 	}
 
 	var resp GetMissionsResponse
@@ -156,11 +211,11 @@ func (h Handler) CreateMission(ctx *fiber.Ctx) error {
 		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse body"))
 	}
 
-	if err := req.Validate(); err != nil {
+	if err := req.Validate(h.service.GetRules()); err != nil {
 		return RespondWithError(ctx, apperrors.InvalidRequest(err))
 	}
 
-	missionID, err := h.service.CreateMission(ctx.Context(), req.Params())
+	missionID, similar, err := h.service.CreateMission(ctx.UserContext(), req.Params())
 	if err != nil {
 		return RespondWithError(ctx, fmt.Errorf("failed to create mission: %w", err))
 	}
@@ -168,6 +223,7 @@ func (h Handler) CreateMission(ctx *fiber.Ctx) error {
 	var resp CreateMissionResponse
 	resp.Ok = true
 	resp.ID = missionID
+	resp.Similar = SimilarTargetsFromModel(similar)
 
 	return ctx.Status(http.StatusCreated).JSON(resp)
 }
@@ -178,7 +234,17 @@ func (h Handler) GetMissionByID(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	mission, err := h.service.GetMissionByID(ctx.Context(), missionID)
+	var req GetMissionRequest
+	if err = ctx.QueryParser(&req); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse query"))
+	}
+
+	expand, err := req.Params()
+	if err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err))
+	}
+
+	mission, err := h.service.GetMissionByID(ctx.UserContext(), missionID, expand)
 	if err != nil {
 		return RespondWithError(ctx, fmt.Errorf("failed to get mission: %w", err))
 	}
@@ -205,9 +271,12 @@ func (h Handler) UpdateMissionByID(ctx *fiber.Ctx) error {
 		return RespondWithError(ctx, apperrors.InvalidRequest(err))
 	}
 
-	err = h.service.UpdateMissionByID(ctx.Context(), dto.UpdateMissionParams{
+	err = h.service.UpdateMissionByID(ctx.UserContext(), dto.UpdateMissionParams{
 		MissionID:     missionID,
 		AssignedCatID: req.AssignedCatID,
+		Budget:        req.Budget,
+		StartsAt:      req.StartsAt,
+		EndsAt:        req.EndsAt,
 	})
 	if err != nil {
 		return RespondWithError(ctx, fmt.Errorf("failed to update cat: %w", err))
@@ -219,6 +288,49 @@ func (h Handler) UpdateMissionByID(ctx *fiber.Ctx) error {
 	return ctx.JSON(resp)
 }
 
+func (h Handler) UnassignMissionByID(ctx *fiber.Ctx) error {
+	missionID, err := h.extractMissionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = h.service.UpdateMissionByID(ctx.UserContext(), dto.UpdateMissionParams{
+		MissionID: missionID,
+		Unassign:  true,
+	})
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to unassign mission: %w", err))
+	}
+
+	var resp BaseResponse
+	resp.Ok = true
+
+	return ctx.JSON(resp)
+}
+
+func (h Handler) GetMissionAssignments(ctx *fiber.Ctx) error {
+	missionID, err := h.extractMissionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	assignments, err := h.service.GetMissionAssignments(ctx.UserContext(), missionID)
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to get mission assignments: %w", err))
+	}
+
+	out := make([]Assignment, len(assignments))
+	for i := range assignments {
+		out[i] = AssignmentFromModel(assignments[i])
+	}
+
+	var resp GetAssignmentsResponse
+	resp.Ok = true
+	resp.Assignments = out
+
+	return ctx.JSON(resp)
+}
+
 func (h Handler) CompleteMissionByID(ctx *fiber.Ctx) error {
 	missionID, err := h.extractMissionID(ctx)
 	if err != nil {
@@ -227,7 +339,7 @@ func (h Handler) CompleteMissionByID(ctx *fiber.Ctx) error {
 
 	isCompleted := true
 
-	err = h.service.UpdateMissionByID(ctx.Context(), dto.UpdateMissionParams{
+	err = h.service.UpdateMissionByID(ctx.UserContext(), dto.UpdateMissionParams{
 		MissionID:   missionID,
 		IsCompleted: &isCompleted,
 	})
@@ -247,7 +359,7 @@ func (h Handler) DeleteMissionByID(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	err = h.service.DeleteMissionByID(ctx.Context(), missionID)
+	err = h.service.DeleteMissionByID(ctx.UserContext(), missionID)
 	if err != nil {
 		return RespondWithError(ctx, fmt.Errorf("failed to delete mission: %w", err))
 	}
@@ -258,6 +370,114 @@ func (h Handler) DeleteMissionByID(ctx *fiber.Ctx) error {
 	return ctx.JSON(resp)
 }
 
+func (h Handler) ReopenMission(ctx *fiber.Ctx) error {
+	missionID, err := h.extractMissionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	var req ReopenRequest
+	if err = ctx.BodyParser(&req); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse body"))
+	}
+
+	if err = req.Validate(); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err))
+	}
+
+	err = h.service.ReopenMission(ctx.UserContext(), missionID, req.Reason)
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to reopen mission: %w", err))
+	}
+
+	var resp BaseResponse
+	resp.Ok = true
+
+	return ctx.JSON(resp)
+}
+
+func (h Handler) GetMissionTimeline(ctx *fiber.Ctx) error {
+	missionID, err := h.extractMissionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	events, err := h.service.GetMissionTimeline(ctx.UserContext(), missionID)
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to get mission timeline: %w", err))
+	}
+
+	out := make([]Event, len(events))
+	for i := range events {
+		out[i] = EventFromModel(events[i])
+	}
+
+	var resp GetMissionTimelineResponse
+	resp.Ok = true
+	resp.Events = out
+
+	return ctx.JSON(resp)
+}
+
+func (h Handler) GetMissionCost(ctx *fiber.Ctx) error {
+	missionID, err := h.extractMissionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	cost, err := h.service.GetMissionCost(ctx.UserContext(), missionID)
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to get mission cost: %w", err))
+	}
+
+	var resp GetMissionCostResponse
+	resp.Ok = true
+	resp.Cost = MissionCostFromModel(cost)
+
+	return ctx.JSON(resp)
+}
+
+func (h Handler) GetMissionCandidates(ctx *fiber.Ctx) error {
+	missionID, err := h.extractMissionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	candidates, err := h.service.GetMissionCandidates(ctx.UserContext(), missionID)
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to get mission candidates: %w", err))
+	}
+
+	out := make([]Candidate, len(candidates))
+	for i := range candidates {
+		out[i] = CandidateFromModel(candidates[i])
+	}
+
+	var resp GetMissionCandidatesResponse
+	resp.Ok = true
+	resp.Candidates = out
+
+	return ctx.JSON(resp)
+}
+
+func (h Handler) AutoAssignMission(ctx *fiber.Ctx) error {
+	missionID, err := h.extractMissionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	catID, err := h.service.AutoAssignMission(ctx.UserContext(), missionID)
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to auto assign mission: %w", err))
+	}
+
+	var resp AutoAssignMissionResponse
+	resp.Ok = true
+	resp.CatID = catID
+
+	return ctx.JSON(resp)
+}
+
 // Targets
 
 func (h Handler) extractTargetID(ctx *fiber.Ctx) (int, error) {
@@ -275,7 +495,12 @@ func (h Handler) GetMissionTargets(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	targets, err := h.service.GetTargetsByMissionID(ctx.Context(), missionID)
+	var req GetNotesRequest
+	if err = ctx.QueryParser(&req); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse query"))
+	}
+
+	targets, err := h.service.GetTargetsByMissionID(ctx.UserContext(), missionID, req.Params())
 	if err != nil {
 		return RespondWithError(ctx, fmt.Errorf("failed to delete mission: %w", err))
 	}
@@ -303,21 +528,95 @@ func (h Handler) AddMissionTargets(ctx *fiber.Ctx) error {
 		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse body"))
 	}
 
-	if err = req.Validate(); err != nil {
+	if err = req.Validate(h.service.GetRules()); err != nil {
 		return RespondWithError(ctx, apperrors.InvalidRequest(err))
 	}
 
-	err = h.service.AddMissionTargets(ctx.Context(), missionID, req.Params())
+	similar, err := h.service.AddMissionTargets(ctx.UserContext(), missionID, req.Params())
 	if err != nil {
 		return RespondWithError(ctx, fmt.Errorf("failed to create mission: %w", err))
 	}
 
-	var resp BaseResponse
+	var resp AddMissionTargetsResponse
 	resp.Ok = true
+	resp.Similar = SimilarTargetsFromModel(similar)
 
 	return ctx.Status(http.StatusCreated).JSON(resp)
 }
 
+func (h Handler) GetNearbyTargets(ctx *fiber.Ctx) error {
+	var req NearbyTargetsRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse query"))
+	}
+
+	if err := req.Validate(); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err))
+	}
+
+	targets, err := h.service.GetNearbyTargets(ctx.UserContext(), req.Params())
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to get nearby targets: %w", err))
+	}
+
+	out := make([]NearbyTarget, len(targets))
+	for i := range targets {
+		out[i] = NearbyTargetFromModel(targets[i])
+	}
+
+	var resp GetNearbyTargetsResponse
+	resp.Ok = true
+	resp.Targets = out
+
+	return ctx.JSON(resp)
+}
+
+func (h Handler) GetCountriesStats(ctx *fiber.Ctx) error {
+	stats, err := h.service.GetCountriesStats(ctx.UserContext())
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to get countries stats: %w", err))
+	}
+
+	out := make([]CountryStats, len(stats))
+	for i := range stats {
+		out[i] = CountryStatsFromModel(stats[i])
+	}
+
+	var resp GetCountriesStatsResponse
+	resp.Ok = true
+	resp.Countries = out
+
+	return ctx.JSON(resp)
+}
+
+func (h Handler) GetMissionTargetsGeoJSON(ctx *fiber.Ctx) error {
+	missionID, err := h.extractMissionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	locations, err := h.service.GetMissionTargetLocations(ctx.UserContext(), missionID)
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to get mission targets locations: %w", err))
+	}
+
+	return ctx.JSON(GeoJSONFromModel(locations), geoJSONContentType)
+}
+
+func (h Handler) GetTargetsGeoJSON(ctx *fiber.Ctx) error {
+	var req GetTargetsGeoJSONRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse query"))
+	}
+
+	locations, err := h.service.GetTargetLocations(ctx.UserContext(), req.Params())
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to get targets locations: %w", err))
+	}
+
+	return ctx.JSON(GeoJSONFromModel(locations), geoJSONContentType)
+}
+
 func (h Handler) GetTargetByID(ctx *fiber.Ctx) error {
 	missionID, err := h.extractMissionID(ctx)
 	if err != nil {
@@ -329,7 +628,12 @@ func (h Handler) GetTargetByID(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	target, err := h.service.GetTargetByID(ctx.Context(), missionID, targetID)
+	var req GetNotesRequest
+	if err = ctx.QueryParser(&req); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse query"))
+	}
+
+	target, err := h.service.GetTargetByID(ctx.UserContext(), missionID, targetID, req.Params())
 	if err != nil {
 		return RespondWithError(ctx, fmt.Errorf("failed to get target by id: %w", err))
 	}
@@ -341,6 +645,47 @@ func (h Handler) GetTargetByID(ctx *fiber.Ctx) error {
 	return ctx.JSON(resp)
 }
 
+func (h Handler) UpdateTargetByID(ctx *fiber.Ctx) error {
+	missionID, err := h.extractMissionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	targetID, err := h.extractTargetID(ctx)
+	if err != nil {
+		return err
+	}
+
+	var req UpdateTargetRequest
+	if err = ctx.BodyParser(&req); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse body"))
+	}
+
+	if err = req.Validate(); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err))
+	}
+
+	err = h.service.UpdateTargetByID(ctx.UserContext(), dto.UpdateTargetParams{
+		MissionID: missionID,
+		TargetID:  targetID,
+		Name:      req.Name,
+		Country:   req.Country,
+		City:      req.City,
+		RiskLevel: req.RiskLevel,
+		Priority:  req.Priority,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+	})
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to update target: %w", err))
+	}
+
+	var resp BaseResponse
+	resp.Ok = true
+
+	return ctx.JSON(resp)
+}
+
 func (h Handler) CompleteTargetByID(ctx *fiber.Ctx) error {
 	missionID, err := h.extractMissionID(ctx)
 	if err != nil {
@@ -352,7 +697,24 @@ func (h Handler) CompleteTargetByID(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	err = h.service.CompleteTargetByID(ctx.Context(), missionID, targetID)
+	// body is optional, it's required only to submit a closing report
+	var req CompleteTargetRequest
+	if len(ctx.Body()) > 0 {
+		if err = ctx.BodyParser(&req); err != nil {
+			return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse body"))
+		}
+	}
+
+	if err = req.Validate(); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err))
+	}
+
+	err = h.service.CompleteTargetByID(ctx.UserContext(), dto.CompleteTargetParams{
+		MissionID:     missionID,
+		TargetID:      targetID,
+		ClosingReport: req.ClosingReport,
+		AuthorCatID:   req.AuthorCatID,
+	})
 	if err != nil {
 		return RespondWithError(ctx, fmt.Errorf("failed to get target by id: %w", err))
 	}
@@ -374,7 +736,7 @@ func (h Handler) DeleteTargetByID(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	err = h.service.DeleteTargetByID(ctx.Context(), missionID, targetID)
+	err = h.service.DeleteTargetByID(ctx.UserContext(), missionID, targetID)
 	if err != nil {
 		return RespondWithError(ctx, fmt.Errorf("failed to get delete target: %w", err))
 	}
@@ -385,9 +747,7 @@ func (h Handler) DeleteTargetByID(ctx *fiber.Ctx) error {
 	return ctx.JSON(resp)
 }
 
-// Notes
-
-func (h Handler) AddTargetNote(ctx *fiber.Ctx) error {
+func (h Handler) ReopenTargetByID(ctx *fiber.Ctx) error {
 	missionID, err := h.extractMissionID(ctx)
 	if err != nil {
 		return err
@@ -398,7 +758,7 @@ func (h Handler) AddTargetNote(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	var req AddTargetNotesRequest
+	var req ReopenRequest
 	if err = ctx.BodyParser(&req); err != nil {
 		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse body"))
 	}
@@ -407,9 +767,541 @@ func (h Handler) AddTargetNote(ctx *fiber.Ctx) error {
 		return RespondWithError(ctx, apperrors.InvalidRequest(err))
 	}
 
-	err = h.service.AddTargetNote(ctx.Context(), missionID, targetID, req.Notes)
+	err = h.service.ReopenTarget(ctx.UserContext(), missionID, targetID, req.Reason)
 	if err != nil {
-		return RespondWithError(ctx, fmt.Errorf("failed to get delete target: %w", err))
+		return RespondWithError(ctx, fmt.Errorf("failed to reopen target: %w", err))
+	}
+
+	var resp BaseResponse
+	resp.Ok = true
+
+	return ctx.JSON(resp)
+}
+
+// Notes
+
+func (h Handler) AddTargetNote(ctx *fiber.Ctx) error {
+	missionID, err := h.extractMissionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	targetID, err := h.extractTargetID(ctx)
+	if err != nil {
+		return err
+	}
+
+	var req AddTargetNotesRequest
+	if err = ctx.BodyParser(&req); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse body"))
+	}
+
+	if err = req.Validate(); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err))
+	}
+
+	// multipart form may carry files attached to the note
+	var attachments []dto.AttachmentFile
+	if strings.HasPrefix(ctx.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		form, err := ctx.MultipartForm()
+		if err != nil {
+			return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse multipart form"))
+		}
+
+		for _, header := range form.File["files"] {
+			file, err := header.Open()
+			if err != nil {
+				return RespondWithError(ctx, apperrors.Internal(err).Wrap("open file"))
+			}
+			defer file.Close()
+
+			attachments = append(attachments, dto.AttachmentFile{
+				FileName: header.Filename,
+				Size:     header.Size,
+				Content:  file,
+			})
+		}
+	}
+
+	err = h.service.AddTargetNote(ctx.UserContext(), dto.CreateNotesParams{
+		MissionID:   missionID,
+		TargetID:    targetID,
+		AuthorCatID: &req.AuthorCatID,
+		Contents:    req.Notes,
+		Attachments: attachments,
+	})
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to get delete target: %w", err))
+	}
+
+	var resp BaseResponse
+	resp.Ok = true
+
+	return ctx.JSON(resp)
+}
+
+func (h Handler) GetTargetNotes(ctx *fiber.Ctx) error {
+	missionID, err := h.extractMissionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	targetID, err := h.extractTargetID(ctx)
+	if err != nil {
+		return err
+	}
+
+	var req GetTargetNotesRequest
+	if err = ctx.QueryParser(&req); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse query"))
+	}
+
+	if err = req.Validate(); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err))
+	}
+
+	params, err := req.Params(missionID, targetID)
+	if err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err))
+	}
+
+	page, err := h.service.GetTargetNotes(ctx.UserContext(), params)
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to get target notes: %w", err))
+	}
+
+	out := make([]Note, len(page.Notes))
+	for i := range page.Notes {
+		out[i] = NoteFromModel(page.Notes[i])
+	}
+
+	var resp GetTargetNotesResponse
+	resp.Ok = true
+	resp.Notes = out
+	if page.NextBefore != nil {
+		cursor := formatNotesCursor(*page.NextBefore)
+		resp.NextBefore = &cursor
+	}
+
+	return ctx.JSON(resp)
+}
+
+func (h Handler) extractNoteID(ctx *fiber.Ctx) (int, error) {
+	noteID, err := ctx.ParamsInt("note_id")
+	if err != nil {
+		return -1, RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse note id"))
+	}
+
+	return noteID, nil
+}
+
+func (h Handler) UpdateTargetNote(ctx *fiber.Ctx) error {
+	missionID, err := h.extractMissionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	targetID, err := h.extractTargetID(ctx)
+	if err != nil {
+		return err
+	}
+
+	noteID, err := h.extractNoteID(ctx)
+	if err != nil {
+		return err
+	}
+
+	var req UpdateNoteRequest
+	if err = ctx.BodyParser(&req); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse body"))
+	}
+
+	if err = req.Validate(); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err))
+	}
+
+	err = h.service.UpdateTargetNote(ctx.UserContext(), dto.UpdateNoteParams{
+		MissionID:   missionID,
+		TargetID:    targetID,
+		NoteID:      noteID,
+		AuthorCatID: &req.AuthorCatID,
+		Content:     req.Content,
+	})
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to update note: %w", err))
+	}
+
+	var resp BaseResponse
+	resp.Ok = true
+
+	return ctx.JSON(resp)
+}
+
+func (h Handler) DeleteTargetNote(ctx *fiber.Ctx) error {
+	missionID, err := h.extractMissionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	targetID, err := h.extractTargetID(ctx)
+	if err != nil {
+		return err
+	}
+
+	noteID, err := h.extractNoteID(ctx)
+	if err != nil {
+		return err
+	}
+
+	var req DeleteNoteRequest
+	if err = ctx.QueryParser(&req); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse query"))
+	}
+
+	if err = req.Validate(); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err))
+	}
+
+	err = h.service.DeleteTargetNote(ctx.UserContext(), dto.DeleteNoteParams{
+		MissionID:   missionID,
+		TargetID:    targetID,
+		NoteID:      noteID,
+		AuthorCatID: &req.AuthorCatID,
+	})
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to delete note: %w", err))
+	}
+
+	var resp BaseResponse
+	resp.Ok = true
+
+	return ctx.JSON(resp)
+}
+
+func (h Handler) extractAttachmentID(ctx *fiber.Ctx) (int, error) {
+	attachmentID, err := ctx.ParamsInt("attachment_id")
+	if err != nil {
+		return -1, RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse attachment id"))
+	}
+
+	return attachmentID, nil
+}
+
+func (h Handler) AddNoteAttachment(ctx *fiber.Ctx) error {
+	missionID, err := h.extractMissionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	targetID, err := h.extractTargetID(ctx)
+	if err != nil {
+		return err
+	}
+
+	noteID, err := h.extractNoteID(ctx)
+	if err != nil {
+		return err
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse file"))
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return RespondWithError(ctx, apperrors.Internal(err).Wrap("open file"))
+	}
+	defer file.Close()
+
+	attachmentID, err := h.service.AddNoteAttachment(ctx.UserContext(), dto.CreateNoteAttachmentParams{
+		MissionID: missionID,
+		TargetID:  targetID,
+		NoteID:    noteID,
+		File: dto.AttachmentFile{
+			FileName: header.Filename,
+			Size:     header.Size,
+			Content:  file,
+		},
+	})
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to add note attachment: %w", err))
+	}
+
+	var resp AddNoteAttachmentResponse
+	resp.Ok = true
+	resp.AttachmentID = attachmentID
+
+	return ctx.JSON(resp)
+}
+
+func (h Handler) GetNoteAttachments(ctx *fiber.Ctx) error {
+	missionID, err := h.extractMissionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	targetID, err := h.extractTargetID(ctx)
+	if err != nil {
+		return err
+	}
+
+	noteID, err := h.extractNoteID(ctx)
+	if err != nil {
+		return err
+	}
+
+	attachments, err := h.service.GetNoteAttachments(ctx.UserContext(), missionID, targetID, noteID)
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to get note attachments: %w", err))
+	}
+
+	out := make([]NoteAttachment, len(attachments))
+	for i := range attachments {
+		out[i] = NoteAttachmentFromModel(attachments[i])
+	}
+
+	var resp GetNoteAttachmentsResponse
+	resp.Ok = true
+	resp.Attachments = out
+
+	return ctx.JSON(resp)
+}
+
+func (h Handler) DownloadNoteAttachment(ctx *fiber.Ctx) error {
+	missionID, err := h.extractMissionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	targetID, err := h.extractTargetID(ctx)
+	if err != nil {
+		return err
+	}
+
+	noteID, err := h.extractNoteID(ctx)
+	if err != nil {
+		return err
+	}
+
+	attachmentID, err := h.extractAttachmentID(ctx)
+	if err != nil {
+		return err
+	}
+
+	attachment, content, err := h.service.GetNoteAttachment(ctx.UserContext(), missionID, targetID, noteID, attachmentID)
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to get note attachment: %w", err))
+	}
+
+	ctx.Attachment(attachment.FileName)
+	ctx.Set(fiber.HeaderContentType, attachment.ContentType)
+	ctx.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+
+	// the stream is closed once the response is sent
+	return ctx.SendStream(content, int(attachment.Size))
+}
+
+func (h Handler) DeleteNoteAttachment(ctx *fiber.Ctx) error {
+	missionID, err := h.extractMissionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	targetID, err := h.extractTargetID(ctx)
+	if err != nil {
+		return err
+	}
+
+	noteID, err := h.extractNoteID(ctx)
+	if err != nil {
+		return err
+	}
+
+	attachmentID, err := h.extractAttachmentID(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = h.service.DeleteNoteAttachment(ctx.UserContext(), missionID, targetID, noteID, attachmentID)
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to delete note attachment: %w", err))
+	}
+
+	var resp BaseResponse
+	resp.Ok = true
+
+	return ctx.JSON(resp)
+}
+
+func (h Handler) SearchNotes(ctx *fiber.Ctx) error {
+	var req SearchNotesRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse query"))
+	}
+
+	if err := req.Validate(); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err))
+	}
+
+	results, err := h.service.SearchNotes(ctx.UserContext(), req.Params())
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to search notes: %w", err))
+	}
+
+	out := make([]NoteSearchResult, len(results))
+	for i := range results {
+		out[i] = NoteSearchResultFromModel(results[i])
+	}
+
+	var resp SearchNotesResponse
+	resp.Ok = true
+	resp.Results = out
+
+	return ctx.JSON(resp)
+}
+
+func (h Handler) GetNoteRevisions(ctx *fiber.Ctx) error {
+	missionID, err := h.extractMissionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	targetID, err := h.extractTargetID(ctx)
+	if err != nil {
+		return err
+	}
+
+	noteID, err := h.extractNoteID(ctx)
+	if err != nil {
+		return err
+	}
+
+	revisions, err := h.service.GetNoteRevisions(ctx.UserContext(), missionID, targetID, noteID)
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to get note revisions: %w", err))
+	}
+
+	out := make([]NoteRevision, len(revisions))
+	for i := range revisions {
+		out[i] = NoteRevisionFromModel(revisions[i])
+	}
+
+	var resp GetNoteRevisionsResponse
+	resp.Ok = true
+	resp.Revisions = out
+
+	return ctx.JSON(resp)
+}
+
+func (h Handler) GetNoteRevisionsDiff(ctx *fiber.Ctx) error {
+	missionID, err := h.extractMissionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	targetID, err := h.extractTargetID(ctx)
+	if err != nil {
+		return err
+	}
+
+	noteID, err := h.extractNoteID(ctx)
+	if err != nil {
+		return err
+	}
+
+	var req GetNoteRevisionsDiffRequest
+	if err = ctx.QueryParser(&req); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse query"))
+	}
+
+	if err = req.Validate(); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err))
+	}
+
+	diff, err := h.service.GetNoteRevisionsDiff(ctx.UserContext(), dto.NoteRevisionsDiffParams{
+		MissionID: missionID,
+		TargetID:  targetID,
+		NoteID:    noteID,
+		From:      req.From,
+		To:        req.To,
+	})
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to get note revisions diff: %w", err))
+	}
+
+	return ctx.SendString(diff)
+}
+
+// Dossiers
+
+func (h Handler) extractPersonID(ctx *fiber.Ctx) (int, error) {
+	personID, err := ctx.ParamsInt("person_id")
+	if err != nil {
+		return -1, RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse person id"))
+	}
+
+	return personID, nil
+}
+
+func (h Handler) CreateDossier(ctx *fiber.Ctx) error {
+	var req CreatePersonRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse body"))
+	}
+
+	if err := req.Validate(); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err))
+	}
+
+	personID, err := h.service.CreatePerson(ctx.UserContext(), req.Params())
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to create person: %w", err))
+	}
+
+	var resp CreatePersonResponse
+	resp.Ok = true
+	resp.ID = personID
+
+	return ctx.Status(http.StatusCreated).JSON(resp)
+}
+
+func (h Handler) GetDossier(ctx *fiber.Ctx) error {
+	personID, err := h.extractPersonID(ctx)
+	if err != nil {
+		return err
+	}
+
+	dossier, err := h.service.GetDossier(ctx.UserContext(), personID)
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to get dossier: %w", err))
+	}
+
+	var resp GetDossierResponse
+	resp.Ok = true
+	resp.Dossier = DossierFromModel(dossier)
+
+	return ctx.JSON(resp)
+}
+
+func (h Handler) LinkDossierTarget(ctx *fiber.Ctx) error {
+	personID, err := h.extractPersonID(ctx)
+	if err != nil {
+		return err
+	}
+
+	var req LinkDossierTargetRequest
+	if err = ctx.BodyParser(&req); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err).Wrap("parse body"))
+	}
+
+	if err = req.Validate(); err != nil {
+		return RespondWithError(ctx, apperrors.InvalidRequest(err))
+	}
+
+	err = h.service.LinkTargetToPerson(ctx.UserContext(), personID, req.MissionID, req.TargetID)
+	if err != nil {
+		return RespondWithError(ctx, fmt.Errorf("failed to link target to dossier: %w", err))
 	}
 
 	var resp BaseResponse
@@ -0,0 +1,28 @@
+package http
+
+import (
+	"crypto/subtle"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/apperrors"
+)
+
+// AdminTokenHeader must hold the configured admin token to access privileged endpoints
+const AdminTokenHeader = "X-Admin-Token"
+
+// PrivilegedMiddleware denies requests without a valid admin token.
+// If no token is configured, privileged endpoints are disabled.
+func PrivilegedMiddleware(adminToken string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if adminToken == "" {
+			return RespondWithError(ctx, apperrors.PermissionDenied("privileged endpoints are disabled"))
+		}
+
+		token := ctx.Get(AdminTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			return RespondWithError(ctx, apperrors.PermissionDenied("invalid admin token"))
+		}
+
+		return ctx.Next()
+	}
+}
@@ -1,8 +1,17 @@
 package http
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/illiafox/spy-cat-test-assignment/app/config"
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
 	"github.com/illiafox/spy-cat-test-assignment/app/internal/service/dto"
 )
 
@@ -22,39 +31,108 @@ func (r CreateCatRequest) Validate() error {
 	)
 }
 
+// default risk level and priority of targets created without them
+const (
+	defaultTargetRiskLevel = models.RiskLevelLow
+	defaultTargetPriority  = 3
+)
+
+var riskLevelRule = validation.In(models.RiskLevelLow, models.RiskLevelMedium, models.RiskLevelHigh)
+
+// priority is from 1 (lowest) to 5 (highest)
+var (
+	minPriorityRule = validation.Min(1)
+	maxPriorityRule = validation.Max(5)
+)
+
 type AddTargetRequest struct {
-	Name    string `json:"name"`
-	Country string `json:"country"`
+	Name      string           `json:"name"`
+	Country   string           `json:"country"`
+	City      *string          `json:"city"`
+	PersonID  *int             `json:"person_id"`
+	RiskLevel models.RiskLevel `json:"risk_level"`
+	Priority  int              `json:"priority"`
+	Latitude  *float64         `json:"latitude"`
+	Longitude *float64         `json:"longitude"`
 }
 
 func (r AddTargetRequest) Validate() error {
 	return validation.ValidateStruct(&r,
 		validation.Field(&r.Name, validation.Required, validation.Length(2, 100)),
 		validation.Field(&r.Country, validation.Required, is.CountryCode2),
+		validation.Field(&r.City, validation.NilOrNotEmpty, validation.Length(1, 100)),
+		validation.Field(&r.PersonID, validation.NilOrNotEmpty, validation.Min(1)),
+		validation.Field(&r.RiskLevel, riskLevelRule),
+		validation.Field(&r.Priority, minPriorityRule, maxPriorityRule),
+		validation.Field(&r.Latitude, latitudeRules(r.Longitude)...),
+		validation.Field(&r.Longitude, longitudeRules(r.Latitude)...),
 	)
 }
 
+func (r AddTargetRequest) Params() dto.CreateTargetParams {
+	params := dto.CreateTargetParams{
+		Name:      r.Name,
+		Country:   r.Country,
+		City:      r.City,
+		PersonID:  r.PersonID,
+		RiskLevel: r.RiskLevel,
+		Priority:  r.Priority,
+		Latitude:  r.Latitude,
+		Longitude: r.Longitude,
+	}
+
+	if params.RiskLevel == "" {
+		params.RiskLevel = defaultTargetRiskLevel
+	}
+
+	if params.Priority == 0 {
+		params.Priority = defaultTargetPriority
+	}
+
+	return params
+}
+
+// latitudeRules validates latitude range, it must be set together with longitude
+func latitudeRules(longitude *float64) []validation.Rule {
+	return []validation.Rule{
+		validation.When(longitude != nil, validation.NotNil),
+		validation.Min(-90.0), validation.Max(90.0),
+	}
+}
+
+// longitudeRules validates longitude range, it must be set together with latitude
+func longitudeRules(latitude *float64) []validation.Rule {
+	return []validation.Rule{
+		validation.When(latitude != nil, validation.NotNil),
+		validation.Min(-180.0), validation.Max(180.0),
+	}
+}
+
 type CreateMissionRequest struct {
-	Targets []AddTargetRequest `json:"targets"`
+	Budget   *int               `json:"budget"`
+	StartsAt *time.Time         `json:"starts_at"`
+	EndsAt   *time.Time         `json:"ends_at"`
+	Targets  []AddTargetRequest `json:"targets"`
 }
 
-func (r CreateMissionRequest) Validate() error {
+func (r CreateMissionRequest) Validate(rules config.Rules) error {
 	return validation.ValidateStruct(&r,
-		validation.Field(&r.Targets, validation.Required, validation.Length(1, 3)),
+		validation.Field(&r.Budget, validation.Min(0)),
+		validation.Field(&r.Targets, validation.Required, validation.Length(rules.MinTargets, rules.MaxTargets)),
 	)
 }
 
 func (r CreateMissionRequest) Params() dto.CreateMissionParams {
 	targets := make([]dto.CreateTargetParams, len(r.Targets))
 	for i := range r.Targets {
-		targets[i] = dto.CreateTargetParams{
-			Name:    r.Targets[i].Name,
-			Country: r.Targets[i].Country,
-		}
+		targets[i] = r.Targets[i].Params()
 	}
 
 	return dto.CreateMissionParams{
-		Targets: targets,
+		Budget:   r.Budget,
+		StartsAt: r.StartsAt,
+		EndsAt:   r.EndsAt,
+		Targets:  targets,
 	}
 }
 
@@ -76,16 +154,71 @@ func (r UpdateCatRequest) Validate() error {
 // Missions
 
 type GetMissionsRequest struct {
-	CatID *int `query:"cat_id"`
+	CatID  *int   `query:"cat_id"`
+	Expand string `query:"expand"`
+}
+
+func (r GetMissionsRequest) Params() (dto.GetMissionsParams, error) {
+	expand, err := parseMissionExpand(r.Expand)
+	if err != nil {
+		return dto.GetMissionsParams{}, err
+	}
+
+	return dto.GetMissionsParams{
+		CatID:  r.CatID,
+		Expand: expand,
+	}, nil
+}
+
+type GetMissionRequest struct {
+	Expand string `query:"expand"`
+}
+
+func (r GetMissionRequest) Params() (dto.MissionExpand, error) {
+	return parseMissionExpand(r.Expand)
+}
+
+// parseMissionExpand parses comma-separated list of relations, e.g. "targets,notes,cat"
+func parseMissionExpand(s string) (expand dto.MissionExpand, err error) {
+	for _, relation := range strings.Split(s, ",") {
+		switch strings.TrimSpace(relation) {
+		case "":
+		case "targets":
+			expand.Targets = true
+		case "notes":
+			expand.Targets = true
+			expand.Notes = true
+		case "cat":
+			expand.Cat = true
+		default:
+			return expand, fmt.Errorf("expand: unknown relation '%s', expected one of: targets, notes, cat", relation)
+		}
+	}
+
+	return expand, nil
 }
 
 type UpdateMissionRequest struct {
-	AssignedCatID *int `json:"assigned_cat_id"`
+	AssignedCatID *int       `json:"assigned_cat_id"`
+	Budget        *int       `json:"budget"`
+	StartsAt      *time.Time `json:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at"`
 }
 
 func (r UpdateMissionRequest) Validate() error {
 	return validation.ValidateStruct(&r,
 		validation.Field(&r.AssignedCatID, validation.Min(0)),
+		validation.Field(&r.Budget, validation.Min(0)),
+	)
+}
+
+type ReopenRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (r ReopenRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Reason, validation.Required, validation.Length(3, 1000)),
 	)
 }
 
@@ -95,29 +228,184 @@ type AddTargetsRequest struct {
 	Targets []AddTargetRequest `json:"targets"`
 }
 
-func (r AddTargetsRequest) Validate() error {
+func (r AddTargetsRequest) Validate(rules config.Rules) error {
 	return validation.ValidateStruct(&r,
-		validation.Field(&r.Targets, validation.Length(1, 3)),
+		validation.Field(&r.Targets, validation.Required, validation.Length(1, rules.MaxTargets)),
 	)
 }
 
 func (r AddTargetsRequest) Params() []dto.CreateTargetParams {
 	targets := make([]dto.CreateTargetParams, len(r.Targets))
 	for i := range targets {
-		targets[i] = dto.CreateTargetParams{
-			Name:    r.Targets[i].Name,
-			Country: r.Targets[i].Country,
-		}
+		targets[i] = r.Targets[i].Params()
 	}
 	return targets
 }
 
+type UpdateTargetRequest struct {
+	Name      *string           `json:"name"`
+	Country   *string           `json:"country"`
+	City      *string           `json:"city"`
+	RiskLevel *models.RiskLevel `json:"risk_level"`
+	Priority  *int              `json:"priority"`
+	Latitude  *float64          `json:"latitude"`
+	Longitude *float64          `json:"longitude"`
+}
+
+// Validate applies the same rules as AddTargetRequest to the provided fields
+func (r UpdateTargetRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Name, validation.NilOrNotEmpty, validation.Length(2, 100)),
+		validation.Field(&r.Country, validation.NilOrNotEmpty, is.CountryCode2),
+		validation.Field(&r.City, validation.NilOrNotEmpty, validation.Length(1, 100)),
+		validation.Field(&r.RiskLevel, validation.NilOrNotEmpty, riskLevelRule),
+		validation.Field(&r.Priority, validation.NilOrNotEmpty, minPriorityRule, maxPriorityRule),
+		validation.Field(&r.Latitude, latitudeRules(r.Longitude)...),
+		validation.Field(&r.Longitude, longitudeRules(r.Latitude)...),
+	)
+}
+
+type NearbyTargetsRequest struct {
+	Latitude  *float64 `query:"lat"`
+	Longitude *float64 `query:"lon"`
+	RadiusKm  float64  `query:"radius_km"`
+}
+
+func (r NearbyTargetsRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Latitude, validation.NotNil, validation.Min(-90.0), validation.Max(90.0)),
+		validation.Field(&r.Longitude, validation.NotNil, validation.Min(-180.0), validation.Max(180.0)),
+		// half of the Earth's circumference covers the whole globe
+		validation.Field(&r.RadiusKm, validation.Required, validation.Min(0.0), validation.Max(20040.0)),
+	)
+}
+
+func (r NearbyTargetsRequest) Params() dto.NearbyTargetsParams {
+	return dto.NearbyTargetsParams{
+		Latitude:  *r.Latitude,
+		Longitude: *r.Longitude,
+		RadiusKm:  r.RadiusKm,
+	}
+}
+
+type GetTargetsGeoJSONRequest struct {
+	Completed *bool `query:"completed"`
+}
+
+func (r GetTargetsGeoJSONRequest) Params() dto.GetTargetsParams {
+	return dto.GetTargetsParams{
+		IsCompleted: r.Completed,
+	}
+}
+
+type CompleteTargetRequest struct {
+	ClosingReport *string `json:"closing_report"`
+	AuthorCatID   *int    `json:"author_cat_id"`
+}
+
+func (r CompleteTargetRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.ClosingReport, validation.NilOrNotEmpty, validation.Length(1, 10000)),
+		validation.Field(&r.AuthorCatID, validation.Required.When(r.ClosingReport != nil), validation.Min(1)),
+	)
+}
+
+type GetNotesRequest struct {
+	AuthorCatID *int `query:"author_cat_id"`
+}
+
+func (r GetNotesRequest) Params() dto.NotesFilter {
+	return dto.NotesFilter{
+		AuthorCatID: r.AuthorCatID,
+	}
+}
+
+const (
+	defaultNotesPageLimit = 20
+	maxNotesPageLimit     = 100
+)
+
+// GetTargetNotesRequest requests a page of notes older than the Before cursor, taken from the previous page
+type GetTargetNotesRequest struct {
+	AuthorCatID *int   `query:"author_cat_id"`
+	Before      string `query:"before"`
+	Limit       int    `query:"limit"`
+}
+
+func (r GetTargetNotesRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Limit, validation.Min(0), validation.Max(maxNotesPageLimit)),
+	)
+}
+
+func (r GetTargetNotesRequest) Params(missionID, targetID int) (dto.GetNotesParams, error) {
+	params := dto.GetNotesParams{
+		MissionID: missionID,
+		TargetID:  targetID,
+		Filter:    dto.NotesFilter{AuthorCatID: r.AuthorCatID},
+		Limit:     r.Limit,
+	}
+
+	if r.Before != "" {
+		cursor, err := parseNotesCursor(r.Before)
+		if err != nil {
+			return dto.GetNotesParams{}, err
+		}
+
+		params.Before = &cursor
+	}
+
+	if params.Limit == 0 {
+		params.Limit = defaultNotesPageLimit
+	}
+
+	return params, nil
+}
+
+// formatNotesCursor encodes the cursor as base64 of "<created_at unix nanoseconds>.<id>"
+func formatNotesCursor(cursor models.NotesCursor) string {
+	raw := strconv.FormatInt(cursor.CreatedAt.UnixNano(), 10) + "." + strconv.Itoa(cursor.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// parseNotesCursor decodes the cursor made by formatNotesCursor
+func parseNotesCursor(s string) (cursor models.NotesCursor, err error) {
+	invalid := errors.New("before: invalid cursor, pass next_before from the previous page")
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor, invalid
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return cursor, invalid
+	}
+
+	nanos, err := strconv.ParseInt(createdAt, 10, 64)
+	if err != nil {
+		return cursor, invalid
+	}
+
+	cursor.ID, err = strconv.Atoi(id)
+	if err != nil || cursor.ID < 1 {
+		return cursor, invalid
+	}
+
+	cursor.CreatedAt = time.Unix(0, nanos).UTC()
+
+	return cursor, nil
+}
+
+// AddTargetNotesRequest is sent either as JSON or as multipart form along with files
 type AddTargetNotesRequest struct {
-	Notes []string `json:"notes"`
+	AuthorCatID int      `json:"author_cat_id" form:"author_cat_id"`
+	Notes       []string `json:"notes" form:"notes"`
 }
 
 func (r AddTargetNotesRequest) Validate() error {
 	return validation.ValidateStruct(&r,
+		validation.Field(&r.AuthorCatID, validation.Required, validation.Min(1)),
 		validation.Field(&r.Notes,
 			validation.Required,
 			validation.Length(1, 10000),
@@ -125,3 +413,120 @@ func (r AddTargetNotesRequest) Validate() error {
 		),
 	)
 }
+
+type UpdateNoteRequest struct {
+	AuthorCatID int    `json:"author_cat_id"`
+	Content     string `json:"content"`
+}
+
+func (r UpdateNoteRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.AuthorCatID, validation.Required, validation.Min(1)),
+		validation.Field(&r.Content, validation.Required, validation.Length(1, 10000)),
+	)
+}
+
+type DeleteNoteRequest struct {
+	AuthorCatID int `query:"author_cat_id"`
+}
+
+func (r DeleteNoteRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.AuthorCatID, validation.Required, validation.Min(1)),
+	)
+}
+
+const (
+	defaultSearchNotesLimit = 20
+	searchNotesDateLayout   = "2006-01-02"
+)
+
+// SearchNotesRequest matches notes created within From and To dates, both inclusive
+type SearchNotesRequest struct {
+	Query     string  `query:"q"`
+	MissionID *int    `query:"mission_id"`
+	TargetID  *int    `query:"target_id"`
+	Country   *string `query:"country"`
+	From      string  `query:"from"`
+	To        string  `query:"to"`
+	Limit     int     `query:"limit"`
+}
+
+func (r SearchNotesRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Query, validation.Required, validation.Length(1, 1000)),
+		validation.Field(&r.Country, validation.NilOrNotEmpty, is.CountryCode2),
+		validation.Field(&r.From, validation.Date(searchNotesDateLayout)),
+		validation.Field(&r.To, validation.Date(searchNotesDateLayout)),
+		validation.Field(&r.Limit, validation.Min(0), validation.Max(100)),
+	)
+}
+
+func (r SearchNotesRequest) Params() dto.SearchNotesParams {
+	params := dto.SearchNotesParams{
+		Query:     r.Query,
+		MissionID: r.MissionID,
+		TargetID:  r.TargetID,
+		Country:   r.Country,
+		Limit:     r.Limit,
+	}
+
+	if params.Limit == 0 {
+		params.Limit = defaultSearchNotesLimit
+	}
+
+	// dates are validated already
+	if from, err := time.Parse(searchNotesDateLayout, r.From); err == nil {
+		params.From = &from
+	}
+
+	if to, err := time.Parse(searchNotesDateLayout, r.To); err == nil {
+		to = to.AddDate(0, 0, 1)
+		params.To = &to
+	}
+
+	return params
+}
+
+type GetNoteRevisionsDiffRequest struct {
+	From int `query:"from"`
+	To   int `query:"to"`
+}
+
+func (r GetNoteRevisionsDiffRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.From, validation.Required, validation.Min(1)),
+		validation.Field(&r.To, validation.Required, validation.Min(1)),
+	)
+}
+
+type CreatePersonRequest struct {
+	Name    string `json:"name"`
+	Country string `json:"country"`
+}
+
+func (r CreatePersonRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Name, validation.Required, validation.Length(2, 100)),
+		validation.Field(&r.Country, validation.Required, is.CountryCode2),
+	)
+}
+
+func (r CreatePersonRequest) Params() dto.CreatePersonParams {
+	return dto.CreatePersonParams{
+		Name:    r.Name,
+		Country: r.Country,
+	}
+}
+
+type LinkDossierTargetRequest struct {
+	MissionID int `json:"mission_id"`
+	TargetID  int `json:"target_id"`
+}
+
+func (r LinkDossierTargetRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.MissionID, validation.Required, validation.Min(1)),
+		validation.Field(&r.TargetID, validation.Required, validation.Min(1)),
+	)
+}
@@ -0,0 +1,43 @@
+package http
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
+)
+
+func TestNotesCursor(t *testing.T) {
+	cursor := models.NotesCursor{
+		CreatedAt: time.Date(2026, 10, 19, 12, 30, 0, 123456000, time.UTC),
+		ID:        42,
+	}
+
+	got, err := parseNotesCursor(formatNotesCursor(cursor))
+	if err != nil {
+		t.Fatalf("parseNotesCursor() error = %v", err)
+	}
+
+	if !got.CreatedAt.Equal(cursor.CreatedAt) || got.ID != cursor.ID {
+		t.Errorf("parseNotesCursor() = %+v, want %+v", got, cursor)
+	}
+
+	encode := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+
+	for _, invalid := range []string{
+		"42",
+		"not base64!",
+		encode("1760877000000000000"),
+		encode("1760877000000000000.0"),
+		encode("1760877000000000000.-1"),
+		encode("yesterday.42"),
+		encode("1760877000000000000.42.1"),
+	} {
+		if _, err = parseNotesCursor(invalid); err == nil {
+			t.Errorf("parseNotesCursor(%q) error = nil, want error", invalid)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package http
 import (
 	"time"
 
+	"github.com/illiafox/spy-cat-test-assignment/app/config"
 	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
 )
 
@@ -10,6 +11,27 @@ type BaseResponse struct {
 	Ok bool `json:"ok"`
 }
 
+type Rules struct {
+	MinTargets              int  `json:"min_targets"`
+	MaxTargets              int  `json:"max_targets"`
+	MaxOpenMissionsPerCat   int  `json:"max_open_missions_per_cat"`
+	FreezeNotesOnCompletion bool `json:"freeze_notes_on_completion"`
+
+	HighRiskMinExperienceYears int `json:"high_risk_min_experience_years"`
+
+	CompletionMinNotes      int  `json:"completion_min_notes"`
+	CompletionRequireReport bool `json:"completion_require_report"`
+}
+
+func RulesFromConfig(rules config.Rules) Rules {
+	return Rules(rules)
+}
+
+type GetRulesResponse struct {
+	BaseResponse
+	Rules Rules `json:"rules"`
+}
+
 type Cat struct {
 	ID              int       `json:"id"`
 	Name            string    `json:"name"`
@@ -39,42 +61,110 @@ type CreateCatResponse struct {
 	ID int `json:"id"`
 }
 
+type CatSchedule struct {
+	Current  []Mission `json:"current"`
+	Upcoming []Mission `json:"upcoming"`
+}
+
+func CatScheduleFromModel(schedule *models.CatSchedule) CatSchedule {
+	out := CatSchedule{
+		Current:  make([]Mission, len(schedule.Current)),
+		Upcoming: make([]Mission, len(schedule.Upcoming)),
+	}
+
+	for i := range schedule.Current {
+		out.Current[i] = MissionFromModel(schedule.Current[i])
+	}
+
+	for i := range schedule.Upcoming {
+		out.Upcoming[i] = MissionFromModel(schedule.Upcoming[i])
+	}
+
+	return out
+}
+
+type GetCatScheduleResponse struct {
+	BaseResponse
+	Schedule CatSchedule `json:"schedule"`
+}
+
 // Missions
 
 type Mission struct {
-	ID            int       `json:"id"`
-	AssignedCatID *int      `json:"assigned_cat_id"`
-	IsCompleted   bool      `json:"is_completed"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            int              `json:"id"`
+	AssignedCatID *int             `json:"assigned_cat_id"`
+	IsCompleted   bool             `json:"is_completed"`
+	Budget        *int             `json:"budget"`
+	StartsAt      *time.Time       `json:"starts_at"`
+	EndsAt        *time.Time       `json:"ends_at"`
+	Cost          int              `json:"cost"`
+	OverBudget    bool             `json:"over_budget"`
+	RiskLevel     models.RiskLevel `json:"risk_level"`
+	AssignedAt    *time.Time       `json:"assigned_at"`
+	CompletedAt   *time.Time       `json:"completed_at"`
+	ReopenedAt    *time.Time       `json:"reopened_at,omitempty"`
+	ReopenedBy    *string          `json:"reopened_by,omitempty"`
+	ReopenReason  *string          `json:"reopen_reason,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
 }
 
-func MissionFromModel(mission *models.Mission) Mission {
-	return Mission(*mission)
+func MissionFromModel(missionFull *models.MissionFull) Mission {
+	mission := Mission{
+		ID:            missionFull.ID,
+		AssignedCatID: missionFull.AssignedCatID,
+		IsCompleted:   missionFull.IsCompleted,
+		Budget:        missionFull.Budget,
+		StartsAt:      missionFull.StartsAt,
+		EndsAt:        missionFull.EndsAt,
+		AssignedAt:    missionFull.AssignedAt,
+		CompletedAt:   missionFull.CompletedAt,
+		ReopenedAt:    missionFull.ReopenedAt,
+		ReopenedBy:    missionFull.ReopenedBy,
+		ReopenReason:  missionFull.ReopenReason,
+		RiskLevel:     missionFull.RiskLevel,
+		CreatedAt:     missionFull.CreatedAt,
+		UpdatedAt:     missionFull.UpdatedAt,
+	}
+
+	if missionFull.Cost != nil {
+		mission.Cost = missionFull.Cost.Cost
+		mission.OverBudget = missionFull.Cost.OverBudget
+	}
+
+	return mission
 }
 
+// MissionFull is a mission with embedded relations, which are omitted if not loaded
 type MissionFull struct {
 	Mission
-	Targets []Target `json:"targets"`
+	Cat     *Cat             `json:"cat,omitempty"`
+	Targets []ExpandedTarget `json:"targets,omitempty"`
 }
 
 func MissionFullFromModel(missionFull *models.MissionFull) MissionFull {
-	mission := MissionFromModel(missionFull.Mission)
+	out := MissionFull{
+		Mission: MissionFromModel(missionFull),
+	}
 
-	targets := make([]Target, len(missionFull.Targets))
-	for i := range targets {
-		targets[i] = TargetFromModel(missionFull.Targets[i])
+	if missionFull.Cat != nil {
+		cat := CatFromModel(missionFull.Cat)
+		out.Cat = &cat
 	}
 
-	return MissionFull{
-		Mission: mission,
-		Targets: targets,
+	if missionFull.Targets != nil {
+		out.Targets = make([]ExpandedTarget, len(missionFull.Targets))
+		for i := range out.Targets {
+			out.Targets[i] = ExpandedTargetFromModel(missionFull.Targets[i])
+		}
 	}
+
+	return out
 }
 
 type GetMissionsResponse struct {
 	BaseResponse
-	Missions []Mission `json:"missions"`
+	Missions []MissionFull `json:"missions"`
 }
 
 type GetMissionResponse struct {
@@ -84,27 +174,209 @@ type GetMissionResponse struct {
 
 type CreateMissionResponse struct {
 	BaseResponse
-	ID int `json:"id"`
+	ID      int             `json:"id"`
+	Similar []SimilarTarget `json:"similar_targets"`
+}
+
+type Assignment struct {
+	ID           int        `json:"id"`
+	MissionID    int        `json:"mission_id"`
+	CatID        int        `json:"cat_id"`
+	CatName      string     `json:"cat_name"`
+	CatSalary    int        `json:"cat_salary"`
+	AssignedAt   time.Time  `json:"assigned_at"`
+	UnassignedAt *time.Time `json:"unassigned_at"`
+}
+
+func AssignmentFromModel(assignment *models.Assignment) Assignment {
+	return Assignment(*assignment)
+}
+
+type GetAssignmentsResponse struct {
+	BaseResponse
+	Assignments []Assignment `json:"assignments"`
+}
+
+type Event struct {
+	ID        int              `json:"id"`
+	MissionID int              `json:"mission_id"`
+	Type      models.EventType `json:"type"`
+	Actor     string           `json:"actor"`
+	Payload   map[string]any   `json:"payload"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+func EventFromModel(event *models.Event) Event {
+	return Event(*event)
+}
+
+type GetMissionTimelineResponse struct {
+	BaseResponse
+	Events []Event `json:"events"`
+}
+
+type MissionCost struct {
+	MissionID     int              `json:"mission_id"`
+	AssignedCatID *int             `json:"assigned_cat_id"`
+	MonthlySalary int              `json:"monthly_salary"`
+	Days          float64          `json:"days"`
+	Cost          int              `json:"cost"`
+	Budget        *int             `json:"budget"`
+	OverBudget    bool             `json:"over_budget"`
+	Assignments   []AssignmentCost `json:"assignments"`
+}
+
+type AssignmentCost struct {
+	CatID         int     `json:"cat_id"`
+	MonthlySalary int     `json:"monthly_salary"`
+	Days          float64 `json:"days"`
+	Cost          int     `json:"cost"`
+}
+
+func MissionCostFromModel(cost *models.MissionCost) MissionCost {
+	assignments := make([]AssignmentCost, len(cost.Assignments))
+	for i, assignment := range cost.Assignments {
+		assignments[i] = AssignmentCost(*assignment)
+	}
+
+	return MissionCost{
+		MissionID:     cost.MissionID,
+		AssignedCatID: cost.AssignedCatID,
+		MonthlySalary: cost.MonthlySalary,
+		Days:          cost.Days,
+		Cost:          cost.Cost,
+		Budget:        cost.Budget,
+		OverBudget:    cost.OverBudget,
+		Assignments:   assignments,
+	}
+}
+
+type GetMissionCostResponse struct {
+	BaseResponse
+	Cost MissionCost `json:"cost"`
+}
+
+type Candidate struct {
+	Cat
+	Score        float64 `json:"score"`
+	SuccessRate  float64 `json:"success_rate"`
+	OpenMissions int     `json:"open_missions"`
+}
+
+func CandidateFromModel(candidate *models.Candidate) Candidate {
+	return Candidate{
+		Cat:          CatFromModel(candidate.Cat),
+		Score:        candidate.Score,
+		SuccessRate:  candidate.SuccessRate,
+		OpenMissions: candidate.OpenMissions,
+	}
+}
+
+type GetMissionCandidatesResponse struct {
+	BaseResponse
+	Candidates []Candidate `json:"candidates"`
+}
+
+type AutoAssignMissionResponse struct {
+	BaseResponse
+	CatID int `json:"cat_id"`
 }
 
 // Targets
 type Target struct {
-	ID          int       `json:"id"`
-	MissionID   int       `json:"mission_id"`
-	IsCompleted bool      `json:"is_completed"`
-	Name        string    `json:"name"`
-	Country     string    `json:"country"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID            int              `json:"id"`
+	MissionID     int              `json:"mission_id"`
+	IsCompleted   bool             `json:"is_completed"`
+	Name          string           `json:"name"`
+	Country       string           `json:"country"`
+	CountryName   *string          `json:"country_name"`
+	CountryRegion *string          `json:"country_region"`
+	City          *string          `json:"city"`
+	PersonID      *int             `json:"person_id"`
+	RiskLevel     models.RiskLevel `json:"risk_level"`
+	Priority      int              `json:"priority"`
+	Latitude      *float64         `json:"latitude"`
+	Longitude     *float64         `json:"longitude"`
+	ReopenedAt    *time.Time       `json:"reopened_at,omitempty"`
+	ReopenedBy    *string          `json:"reopened_by,omitempty"`
+	ReopenReason  *string          `json:"reopen_reason,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
 }
 
 func TargetFromModel(target *models.Target) Target {
-	return Target(*target)
+	out := Target{
+		ID:           target.ID,
+		MissionID:    target.MissionID,
+		IsCompleted:  target.IsCompleted,
+		Name:         target.Name,
+		Country:      target.Country,
+		City:         target.City,
+		PersonID:     target.PersonID,
+		RiskLevel:    target.RiskLevel,
+		Priority:     target.Priority,
+		Latitude:     target.Latitude,
+		Longitude:    target.Longitude,
+		ReopenedAt:   target.ReopenedAt,
+		ReopenedBy:   target.ReopenedBy,
+		ReopenReason: target.ReopenReason,
+		CreatedAt:    target.CreatedAt,
+		UpdatedAt:    target.UpdatedAt,
+	}
+
+	if target.CountryInfo != nil {
+		out.CountryName = &target.CountryInfo.Name
+		out.CountryRegion = &target.CountryInfo.Region
+	}
+
+	return out
+}
+
+type NearbyTarget struct {
+	Target
+	DistanceKm float64 `json:"distance_km"`
+}
+
+func NearbyTargetFromModel(target *models.NearbyTarget) NearbyTarget {
+	return NearbyTarget{
+		Target:     TargetFromModel(target.Target),
+		DistanceKm: target.DistanceKm,
+	}
+}
+
+// SimilarTarget is a target of another mission matching the new target at Index by name and country
+type SimilarTarget struct {
+	Index  int    `json:"index"`
+	Target Target `json:"target"`
+}
+
+func SimilarTargetsFromModel(similar []*models.SimilarTarget) []SimilarTarget {
+	out := make([]SimilarTarget, len(similar))
+	for i := range similar {
+		out[i] = SimilarTarget{
+			Index:  similar[i].KeyIndex,
+			Target: TargetFromModel(similar[i].Target),
+		}
+	}
+
+	return out
+}
+
+type AddMissionTargetsResponse struct {
+	BaseResponse
+	Similar []SimilarTarget `json:"similar_targets"`
+}
+
+type GetNearbyTargetsResponse struct {
+	BaseResponse
+	Targets []NearbyTarget `json:"targets"`
 }
 
+// TargetFull is a target with its latest notes, NotesCount counts all of them
 type TargetFull struct {
 	Target
-	Notes []Note `json:"notes"`
+	Notes      []Note `json:"notes"`
+	NotesCount int    `json:"notes_count"`
 }
 
 func TargetFullFromModel(targetFull *models.TargetFull) TargetFull {
@@ -116,9 +388,34 @@ func TargetFullFromModel(targetFull *models.TargetFull) TargetFull {
 	}
 
 	return TargetFull{
-		Target: target,
-		Notes:  notes,
+		Target:     target,
+		Notes:      notes,
+		NotesCount: targetFull.NotesCount,
+	}
+}
+
+// ExpandedTarget is a target embedded into a mission, notes are omitted if not loaded
+type ExpandedTarget struct {
+	Target
+	Notes      []Note `json:"notes,omitempty"`
+	NotesCount *int   `json:"notes_count,omitempty"`
+}
+
+func ExpandedTargetFromModel(targetFull *models.TargetFull) ExpandedTarget {
+	out := ExpandedTarget{
+		Target: TargetFromModel(targetFull.Target),
 	}
+
+	if targetFull.Notes != nil {
+		out.Notes = make([]Note, len(targetFull.Notes))
+		for i := range out.Notes {
+			out.Notes[i] = NoteFromModel(targetFull.Notes[i])
+		}
+
+		out.NotesCount = &targetFull.NotesCount
+	}
+
+	return out
 }
 
 type GetMissionTargetsResponse struct {
@@ -134,12 +431,247 @@ type GetTargetResponse struct {
 // Notes
 
 type Note struct {
+	ID          int       `json:"id"`
+	MissionID   int       `json:"-"`
+	TargetID    int       `json:"-"`
+	AuthorCatID *int      `json:"author_cat_id"`
+	Content     string    `json:"content"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func NoteFromModel(note *models.Note) Note {
+	return Note(*note)
+}
+
+type GetTargetNotesResponse struct {
+	BaseResponse
+	Notes []Note `json:"notes"`
+	// opaque cursor of the next page, null on the last one
+	NextBefore *string `json:"next_before"`
+}
+
+type NoteAttachment struct {
+	ID          int       `json:"id"`
+	NoteID      int       `json:"note_id"`
+	MissionID   int       `json:"-"`
+	TargetID    int       `json:"-"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	StorageKey  string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func NoteAttachmentFromModel(attachment *models.NoteAttachment) NoteAttachment {
+	return NoteAttachment(*attachment)
+}
+
+type AddNoteAttachmentResponse struct {
+	BaseResponse
+	AttachmentID int `json:"attachment_id"`
+}
+
+type GetNoteAttachmentsResponse struct {
+	BaseResponse
+	Attachments []NoteAttachment `json:"attachments"`
+}
+
+type NoteSearchResult struct {
+	Note
+	MissionID  int     `json:"mission_id"`
+	TargetID   int     `json:"target_id"`
+	TargetName string  `json:"target_name"`
+	Country    string  `json:"country"`
+	Rank       float64 `json:"rank"`
+	Snippet    string  `json:"snippet"`
+}
+
+func NoteSearchResultFromModel(result *models.NoteSearchResult) NoteSearchResult {
+	return NoteSearchResult{
+		Note:       NoteFromModel(result.Note),
+		MissionID:  result.MissionID,
+		TargetID:   result.TargetID,
+		TargetName: result.TargetName,
+		Country:    result.Country,
+		Rank:       result.Rank,
+		Snippet:    result.Snippet,
+	}
+}
+
+type SearchNotesResponse struct {
+	BaseResponse
+	Results []NoteSearchResult `json:"results"`
+}
+
+type NoteRevision struct {
 	ID        int       `json:"-"`
+	NoteID    int       `json:"note_id"`
+	MissionID int       `json:"-"`
 	TargetID  int       `json:"-"`
+	Revision  int       `json:"revision"`
 	Content   string    `json:"content"`
+	Editor    string    `json:"editor"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
-func NoteFromModel(note *models.Note) Note {
-	return Note(*note)
+func NoteRevisionFromModel(revision *models.NoteRevision) NoteRevision {
+	return NoteRevision(*revision)
+}
+
+type GetNoteRevisionsResponse struct {
+	BaseResponse
+	Revisions []NoteRevision `json:"revisions"`
+}
+
+// Dossiers
+
+type Person struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Country   string    `json:"country"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func PersonFromModel(person *models.Person) Person {
+	return Person(*person)
+}
+
+type CreatePersonResponse struct {
+	BaseResponse
+	ID int `json:"id"`
+}
+
+type Dossier struct {
+	Person
+	Targets []TargetFull `json:"targets"`
+}
+
+func DossierFromModel(dossier *models.Dossier) Dossier {
+	targets := make([]TargetFull, len(dossier.Targets))
+	for i := range targets {
+		targets[i] = TargetFullFromModel(dossier.Targets[i])
+	}
+
+	return Dossier{
+		Person:  PersonFromModel(dossier.Person),
+		Targets: targets,
+	}
+}
+
+type GetDossierResponse struct {
+	BaseResponse
+	Dossier Dossier `json:"dossier"`
+}
+
+// Countries
+
+type CountryStats struct {
+	Country           string  `json:"country"`
+	Name              *string `json:"name"`
+	Region            *string `json:"region"`
+	OpenTargets       int     `json:"open_targets"`
+	CompletedTargets  int     `json:"completed_targets"`
+	OpenMissions      int     `json:"open_missions"`
+	CompletedMissions int     `json:"completed_missions"`
+}
+
+func CountryStatsFromModel(stats *models.CountryStats) CountryStats {
+	out := CountryStats{
+		Country:           stats.Country,
+		OpenTargets:       stats.OpenTargets,
+		CompletedTargets:  stats.CompletedTargets,
+		OpenMissions:      stats.OpenMissions,
+		CompletedMissions: stats.CompletedMissions,
+	}
+
+	if stats.CountryInfo != nil {
+		out.Name = &stats.CountryInfo.Name
+		out.Region = &stats.CountryInfo.Region
+	}
+
+	return out
+}
+
+type GetCountriesStatsResponse struct {
+	BaseResponse
+	Countries []CountryStats `json:"countries"`
+}
+
+// GeoJSON
+
+const geoJSONContentType = "application/geo+json"
+
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []GeoJSONFeature `json:"features"`
+}
+
+type GeoJSONFeature struct {
+	Type       string                  `json:"type"`
+	Geometry   *GeoJSONPoint           `json:"geometry"`
+	Properties TargetFeatureProperties `json:"properties"`
+}
+
+// GeoJSONPoint coordinates are in [longitude, latitude] order, as the spec requires
+type GeoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+type TargetFeatureProperties struct {
+	TargetID             int                         `json:"target_id"`
+	MissionID            int                         `json:"mission_id"`
+	Name                 string                      `json:"name"`
+	Country              string                      `json:"country"`
+	CountryName          *string                     `json:"country_name"`
+	CountryRegion        *string                     `json:"country_region"`
+	City                 *string                     `json:"city"`
+	IsCompleted          bool                        `json:"is_completed"`
+	LocationSource       models.TargetLocationSource `json:"location_source,omitempty"`
+	MissionIsCompleted   bool                        `json:"mission_is_completed"`
+	MissionAssignedCatID *int                        `json:"mission_assigned_cat_id"`
+}
+
+func GeoJSONFromModel(locations []*models.TargetLocation) GeoJSONFeatureCollection {
+	features := make([]GeoJSONFeature, len(locations))
+	for i, location := range locations {
+		feature := GeoJSONFeature{
+			Type: "Feature",
+			Properties: TargetFeatureProperties{
+				TargetID:       location.ID,
+				MissionID:      location.MissionID,
+				Name:           location.Name,
+				Country:        location.Country,
+				City:           location.City,
+				IsCompleted:    location.IsCompleted,
+				LocationSource: location.Source,
+			},
+		}
+
+		if location.Latitude != nil && location.Longitude != nil {
+			feature.Geometry = &GeoJSONPoint{
+				Type:        "Point",
+				Coordinates: [2]float64{*location.Longitude, *location.Latitude},
+			}
+		}
+
+		if location.CountryInfo != nil {
+			feature.Properties.CountryName = &location.CountryInfo.Name
+			feature.Properties.CountryRegion = &location.CountryInfo.Region
+		}
+
+		if location.Mission != nil {
+			feature.Properties.MissionIsCompleted = location.Mission.IsCompleted
+			feature.Properties.MissionAssignedCatID = location.Mission.AssignedCatID
+		}
+
+		features[i] = feature
+	}
+
+	return GeoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: features,
+	}
 }
@@ -10,6 +10,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// multipartOverhead is the room for multipart form fields and boundaries of attachment uploads
+const multipartOverhead = 64 << 10
+
 type Server struct {
 	app     *fiber.App
 	cfg     *config.Config
@@ -21,6 +24,8 @@ func NewServer(cfg *config.Config, logger *zap.Logger, service Service) *Server
 	app := fiber.New(fiber.Config{
 		AppName:               "spy-cats-api",
 		DisableStartupMessage: !cfg.Debug,
+		// attachments are limited by the service, leave room for the rest of multipart form
+		BodyLimit: max(fiber.DefaultBodyLimit, int(cfg.Attachments.MaxSize)+multipartOverhead),
 	})
 
 	server := &Server{
@@ -50,8 +55,12 @@ func (s *Server) setupRoutes(logger *zap.Logger) {
 	s.app.Use(ErrorMiddleware(logger.With(
 		zap.String("server", "fiber"),
 	)))
+	s.app.Use(ActorMiddleware())
 
 	handler := Handler{s.service}
+	privileged := PrivilegedMiddleware(s.cfg.AdminToken)
+
+	s.app.Get("/rules", handler.GetRules)
 
 	s.app.Route("/cats", func(router fiber.Router) {
 		router.Get("/", handler.GetCats)
@@ -61,9 +70,31 @@ func (s *Server) setupRoutes(logger *zap.Logger) {
 			router.Get("/", handler.GetCatByID)
 			router.Patch("/", handler.UpdateCatByID)
 			router.Delete("/", handler.DeleteCatByID)
+			router.Get("/assignments", handler.GetCatAssignments)
+			router.Get("/schedule", handler.GetCatSchedule)
 		})
 	})
 
+	s.app.Get("/countries/stats", handler.GetCountriesStats)
+
+	s.app.Route("/dossiers", func(router fiber.Router) {
+		router.Post("/", handler.CreateDossier)
+
+		router.Route("/:person_id", func(router fiber.Router) {
+			router.Get("/", handler.GetDossier)
+			router.Post("/targets", handler.LinkDossierTarget)
+		})
+	})
+
+	s.app.Route("/search", func(router fiber.Router) {
+		router.Get("/notes", handler.SearchNotes)
+	})
+
+	s.app.Get("/targets.geojson", handler.GetTargetsGeoJSON)
+	s.app.Route("/targets", func(router fiber.Router) {
+		router.Get("/nearby", handler.GetNearbyTargets)
+	})
+
 	s.app.Route("/missions", func(router fiber.Router) {
 		router.Get("/", handler.GetMissions)
 		router.Post("/", handler.CreateMission)
@@ -72,18 +103,37 @@ func (s *Server) setupRoutes(logger *zap.Logger) {
 			router.Get("/", handler.GetMissionByID)
 			router.Patch("/", handler.UpdateMissionByID)
 			router.Post("/complete", handler.CompleteMissionByID)
+			router.Post("/unassign", handler.UnassignMissionByID)
+			router.Get("/assignments", handler.GetMissionAssignments)
+			router.Post("/reopen", privileged, handler.ReopenMission)
 			router.Delete("/", handler.DeleteMissionByID)
+			router.Get("/cost", handler.GetMissionCost)
+			router.Get("/timeline", handler.GetMissionTimeline)
+			router.Get("/candidates", handler.GetMissionCandidates)
+			router.Post("/auto-assign", handler.AutoAssignMission)
 
+			router.Get("/targets.geojson", handler.GetMissionTargetsGeoJSON)
 			router.Route("/targets", func(router fiber.Router) {
 				router.Get("/", handler.GetMissionTargets)
 				router.Post("/add", handler.AddMissionTargets)
 
 				router.Route("/:target_id", func(router fiber.Router) {
 					router.Get("/", handler.GetTargetByID)
+					router.Patch("/", handler.UpdateTargetByID)
 					router.Post("/complete", handler.CompleteTargetByID)
+					router.Post("/reopen", privileged, handler.ReopenTargetByID)
 					router.Delete("/", handler.DeleteTargetByID)
 
+					router.Get("/notes", handler.GetTargetNotes)
 					router.Post("/notes/add", handler.AddTargetNote)
+					router.Patch("/notes/:note_id", handler.UpdateTargetNote)
+					router.Delete("/notes/:note_id", handler.DeleteTargetNote)
+					router.Get("/notes/:note_id/revisions", handler.GetNoteRevisions)
+					router.Get("/notes/:note_id/revisions/diff", handler.GetNoteRevisionsDiff)
+					router.Get("/notes/:note_id/attachments", handler.GetNoteAttachments)
+					router.Post("/notes/:note_id/attachments", handler.AddNoteAttachment)
+					router.Get("/notes/:note_id/attachments/:attachment_id", handler.DownloadNoteAttachment)
+					router.Delete("/notes/:note_id/attachments/:attachment_id", handler.DeleteNoteAttachment)
 				})
 			})
 		})
@@ -2,26 +2,56 @@ package http
 
 import (
 	"context"
+	"io"
 
+	"github.com/illiafox/spy-cat-test-assignment/app/config"
 	"github.com/illiafox/spy-cat-test-assignment/app/internal/models"
 	"github.com/illiafox/spy-cat-test-assignment/app/internal/service/dto"
 )
 
 type Service interface {
+	GetRules() config.Rules
 	AddCat(ctx context.Context, params dto.CreateCatParams) (catID int, err error)
 	GetCats(ctx context.Context) ([]*models.Cat, error)
 	GetCatByID(ctx context.Context, catID int) (*models.Cat, error)
 	UpdateCatByID(ctx context.Context, params dto.UpdateCatParams) error
 	DeleteCatByID(ctx context.Context, catID int) error
-	GetMissions(ctx context.Context, params dto.GetMissionsParams) ([]*models.Mission, error)
-	CreateMission(ctx context.Context, params dto.CreateMissionParams) (missionID int, err error)
-	AddMissionTargets(ctx context.Context, missionID int, newTargets []dto.CreateTargetParams) (err error)
-	GetMissionByID(ctx context.Context, missionID int) (out *models.MissionFull, err error)
+	GetCatSchedule(ctx context.Context, catID int) (out *models.CatSchedule, err error)
+	GetCatAssignments(ctx context.Context, catID int) (out []*models.Assignment, err error)
+	GetMissions(ctx context.Context, params dto.GetMissionsParams) ([]*models.MissionFull, error)
+	CreateMission(ctx context.Context, params dto.CreateMissionParams) (missionID int, similar []*models.SimilarTarget, err error)
+	AddMissionTargets(ctx context.Context, missionID int, newTargets []dto.CreateTargetParams) (similar []*models.SimilarTarget, err error)
+	GetMissionByID(ctx context.Context, missionID int, expand dto.MissionExpand) (out *models.MissionFull, err error)
 	UpdateMissionByID(ctx context.Context, params dto.UpdateMissionParams) (err error)
 	DeleteMissionByID(ctx context.Context, missionID int) (err error)
-	GetTargetsByMissionID(ctx context.Context, missionID int) (out []*models.TargetFull, err error)
-	GetTargetByID(ctx context.Context, missionID int, targetID int) (out *models.TargetFull, err error)
-	CompleteTargetByID(ctx context.Context, missionID int, targetID int) (err error)
+	ReopenMission(ctx context.Context, missionID int, reason string) (err error)
+	GetMissionAssignments(ctx context.Context, missionID int) (out []*models.Assignment, err error)
+	GetMissionTimeline(ctx context.Context, missionID int) (out []*models.Event, err error)
+	GetMissionCost(ctx context.Context, missionID int) (*models.MissionCost, error)
+	GetMissionCandidates(ctx context.Context, missionID int) (out []*models.Candidate, err error)
+	AutoAssignMission(ctx context.Context, missionID int) (catID int, err error)
+	GetTargetsByMissionID(ctx context.Context, missionID int, filter dto.NotesFilter) (out []*models.TargetFull, err error)
+	GetNearbyTargets(ctx context.Context, params dto.NearbyTargetsParams) ([]*models.NearbyTarget, error)
+	CreatePerson(ctx context.Context, params dto.CreatePersonParams) (personID int, err error)
+	GetDossier(ctx context.Context, personID int) (*models.Dossier, error)
+	LinkTargetToPerson(ctx context.Context, personID int, missionID int, targetID int) error
+	GetCountriesStats(ctx context.Context) ([]*models.CountryStats, error)
+	GetMissionTargetLocations(ctx context.Context, missionID int) ([]*models.TargetLocation, error)
+	GetTargetLocations(ctx context.Context, params dto.GetTargetsParams) ([]*models.TargetLocation, error)
+	GetTargetByID(ctx context.Context, missionID int, targetID int, filter dto.NotesFilter) (out *models.TargetFull, err error)
+	UpdateTargetByID(ctx context.Context, params dto.UpdateTargetParams) (err error)
+	CompleteTargetByID(ctx context.Context, params dto.CompleteTargetParams) (err error)
 	DeleteTargetByID(ctx context.Context, missionID int, targetID int) (err error)
-	AddTargetNote(ctx context.Context, missionID int, targetID int, contents []string) (err error)
+	ReopenTarget(ctx context.Context, missionID int, targetID int, reason string) (err error)
+	UpdateTargetNote(ctx context.Context, params dto.UpdateNoteParams) (err error)
+	DeleteTargetNote(ctx context.Context, params dto.DeleteNoteParams) (err error)
+	AddNoteAttachment(ctx context.Context, params dto.CreateNoteAttachmentParams) (attachmentID int, err error)
+	GetNoteAttachments(ctx context.Context, missionID int, targetID int, noteID int) ([]*models.NoteAttachment, error)
+	GetNoteAttachment(ctx context.Context, missionID int, targetID int, noteID int, attachmentID int) (*models.NoteAttachment, io.ReadCloser, error)
+	DeleteNoteAttachment(ctx context.Context, missionID int, targetID int, noteID int, attachmentID int) (err error)
+	GetTargetNotes(ctx context.Context, params dto.GetNotesParams) (out *models.NotesPage, err error)
+	SearchNotes(ctx context.Context, params dto.SearchNotesParams) ([]*models.NoteSearchResult, error)
+	GetNoteRevisions(ctx context.Context, missionID int, targetID int, noteID int) ([]*models.NoteRevision, error)
+	GetNoteRevisionsDiff(ctx context.Context, params dto.NoteRevisionsDiffParams) (string, error)
+	AddTargetNote(ctx context.Context, params dto.CreateNotesParams) (err error)
 }
@@ -0,0 +1,21 @@
+package actor
+
+import "context"
+
+// Anonymous is used when request doesn't specify who performs it
+const Anonymous = "anonymous"
+
+type actorKey struct{}
+
+func Inject(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func Extract(ctx context.Context) string {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	if !ok || actor == "" {
+		return Anonymous
+	}
+
+	return actor
+}
@@ -0,0 +1,176 @@
+// Package envelope implements envelope encryption with AES-256-GCM.
+// Every message is encrypted with its own random data key, which is encrypted (wrapped) with a master key.
+// Master keys are identified by ids, so they can be rotated by re-wrapping data keys only.
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the size of master and data keys, AES-256 is used
+const KeySize = 32
+
+var ErrUnknownKey = errors.New("unknown master key")
+
+// Sealed is an encrypted message along with its data key wrapped with the master key KeyID
+type Sealed struct {
+	KeyID      string
+	DataKey    []byte
+	Ciphertext []byte
+}
+
+// Keyring holds master keys, new messages are sealed with the primary one
+type Keyring struct {
+	primaryID string
+	keys      map[string]cipher.AEAD
+}
+
+func NewKeyring(keys map[string][]byte, primaryID string) (*Keyring, error) {
+	if _, ok := keys[primaryID]; !ok {
+		return nil, fmt.Errorf("primary key '%s': %w", primaryID, ErrUnknownKey)
+	}
+
+	keyring := &Keyring{
+		primaryID: primaryID,
+		keys:      make(map[string]cipher.AEAD, len(keys)),
+	}
+
+	for id, key := range keys {
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("key '%s': %w", id, err)
+		}
+
+		keyring.keys[id] = aead
+	}
+
+	return keyring, nil
+}
+
+func (k *Keyring) PrimaryID() string {
+	return k.primaryID
+}
+
+// Seal encrypts the message with a new data key wrapped with the primary master key.
+// Additional data is authenticated but not encrypted, the same one must be passed to Open.
+func (k *Keyring) Seal(plaintext, additionalData []byte) (*Sealed, error) {
+	dataKey := make([]byte, KeySize)
+
+	_, err := rand.Read(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("generate data key: %w", err)
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := seal(aead, plaintext, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt message: %w", err)
+	}
+
+	wrappedKey, err := seal(k.keys[k.primaryID], dataKey, []byte(k.primaryID))
+	if err != nil {
+		return nil, fmt.Errorf("wrap data key: %w", err)
+	}
+
+	return &Sealed{
+		KeyID:      k.primaryID,
+		DataKey:    wrappedKey,
+		Ciphertext: ciphertext,
+	}, nil
+}
+
+func (k *Keyring) Open(sealed *Sealed, additionalData []byte) ([]byte, error) {
+	dataKey, err := k.unwrap(sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := open(aead, sealed.Ciphertext, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt message: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+// Rewrap wraps the data key of the message with the primary master key, the ciphertext is left as is
+func (k *Keyring) Rewrap(sealed *Sealed) (*Sealed, error) {
+	dataKey, err := k.unwrap(sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	wrappedKey, err := seal(k.keys[k.primaryID], dataKey, []byte(k.primaryID))
+	if err != nil {
+		return nil, fmt.Errorf("wrap data key: %w", err)
+	}
+
+	return &Sealed{
+		KeyID:      k.primaryID,
+		DataKey:    wrappedKey,
+		Ciphertext: sealed.Ciphertext,
+	}, nil
+}
+
+func (k *Keyring) unwrap(sealed *Sealed) ([]byte, error) {
+	master, ok := k.keys[sealed.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("key '%s': %w", sealed.KeyID, ErrUnknownKey)
+	}
+
+	// the key id is authenticated, so a data key can't be passed off as wrapped with another master key
+	dataKey, err := open(master, sealed.DataKey, []byte(sealed.KeyID))
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key: %w", err)
+	}
+
+	return dataKey, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes long, got %d", KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal prepends a random nonce to the ciphertext
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
@@ -0,0 +1,177 @@
+package envelope
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func newTestKeyring(t *testing.T, primaryID string, ids ...string) *Keyring {
+	t.Helper()
+
+	// keys are derived from ids, so keyrings sharing an id share the key
+	keys := make(map[string][]byte, len(ids))
+	for _, id := range ids {
+		keys[id] = bytes.Repeat([]byte(id), KeySize)[:KeySize]
+	}
+
+	keyring, err := NewKeyring(keys, primaryID)
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+
+	return keyring
+}
+
+func TestKeyring(t *testing.T) {
+	keyring := newTestKeyring(t, "old", "old", "new")
+	plaintext := []byte("target seen near the harbour")
+	additionalData := []byte("notes/1")
+
+	sealed, err := keyring.Seal(plaintext, additionalData)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	if sealed.KeyID != "old" {
+		t.Errorf("sealed with key %q, want %q", sealed.KeyID, "old")
+	}
+
+	if bytes.Contains(sealed.Ciphertext, plaintext) {
+		t.Errorf("ciphertext contains the plaintext")
+	}
+
+	tamper := func(b []byte) []byte {
+		b = bytes.Clone(b)
+		b[len(b)-1] ^= 1
+		return b
+	}
+
+	tests := []struct {
+		name           string
+		sealed         *Sealed
+		additionalData []byte
+		wantErr        bool
+		wantUnknownKey bool
+	}{
+		{
+			name:           "round trip",
+			sealed:         sealed,
+			additionalData: additionalData,
+		},
+		{
+			name:           "tampered ciphertext",
+			sealed:         &Sealed{KeyID: sealed.KeyID, DataKey: sealed.DataKey, Ciphertext: tamper(sealed.Ciphertext)},
+			additionalData: additionalData,
+			wantErr:        true,
+		},
+		{
+			name:           "tampered data key",
+			sealed:         &Sealed{KeyID: sealed.KeyID, DataKey: tamper(sealed.DataKey), Ciphertext: sealed.Ciphertext},
+			additionalData: additionalData,
+			wantErr:        true,
+		},
+		{
+			name:           "wrong additional data",
+			sealed:         sealed,
+			additionalData: []byte("notes/2"),
+			wantErr:        true,
+		},
+		{
+			name:           "no additional data",
+			sealed:         sealed,
+			additionalData: nil,
+			wantErr:        true,
+		},
+		{
+			name:           "data key passed off as wrapped with another key",
+			sealed:         &Sealed{KeyID: "new", DataKey: sealed.DataKey, Ciphertext: sealed.Ciphertext},
+			additionalData: additionalData,
+			wantErr:        true,
+		},
+		{
+			name:           "unknown key",
+			sealed:         &Sealed{KeyID: "gone", DataKey: sealed.DataKey, Ciphertext: sealed.Ciphertext},
+			additionalData: additionalData,
+			wantErr:        true,
+			wantUnknownKey: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := keyring.Open(tt.sealed, tt.additionalData)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Open succeeded, want error")
+				}
+
+				if unknown := errors.Is(err, ErrUnknownKey); unknown != tt.wantUnknownKey {
+					t.Errorf("errors.Is(err, ErrUnknownKey) = %v, want %v: %v", unknown, tt.wantUnknownKey, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+
+			if !bytes.Equal(got, plaintext) {
+				t.Errorf("Open = %q, want %q", got, plaintext)
+			}
+		})
+	}
+}
+
+func TestKeyringRewrap(t *testing.T) {
+	plaintext := []byte("target seen near the harbour")
+	additionalData := []byte("notes/1")
+
+	sealed, err := newTestKeyring(t, "old", "old", "new").Seal(plaintext, additionalData)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	rotated := newTestKeyring(t, "new", "old", "new")
+
+	rewrapped, err := rotated.Rewrap(sealed)
+	if err != nil {
+		t.Fatalf("Rewrap: %v", err)
+	}
+
+	if rewrapped.KeyID != "new" {
+		t.Errorf("rewrapped with key %q, want %q", rewrapped.KeyID, "new")
+	}
+
+	if !bytes.Equal(rewrapped.Ciphertext, sealed.Ciphertext) {
+		t.Errorf("ciphertext changed on rewrap")
+	}
+
+	// the old key is no longer needed
+	got, err := newTestKeyring(t, "new", "new").Open(rewrapped, additionalData)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("Open = %q, want %q", got, plaintext)
+	}
+
+	_, err = rotated.Rewrap(&Sealed{KeyID: "gone", DataKey: sealed.DataKey, Ciphertext: sealed.Ciphertext})
+	if !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Rewrap with unknown key: got %v, want %v", err, ErrUnknownKey)
+	}
+}
+
+func TestNewKeyring(t *testing.T) {
+	_, err := NewKeyring(map[string][]byte{"old": bytes.Repeat([]byte{1}, KeySize)}, "new")
+	if !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("unknown primary key: got %v, want %v", err, ErrUnknownKey)
+	}
+
+	_, err = NewKeyring(map[string][]byte{"short": make([]byte, KeySize-1)}, "short")
+	if err == nil {
+		t.Errorf("short key: got nil error")
+	}
+}
@@ -0,0 +1,268 @@
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ContextLines is the number of unchanged lines shown around changes
+const ContextLines = 3
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type op struct {
+	kind opKind
+	line string
+}
+
+// Unified renders line-based difference between two texts in unified diff format.
+// Empty string is returned if texts are equal.
+func Unified(fromName, toName, from, to string) string {
+	ops := diffLines(splitLines(from), splitLines(to))
+
+	hunks := groupHunks(ops)
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromName, toName)
+	for _, h := range hunks {
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(h.fromStart, h.fromCount), hunkRange(h.toStart, h.toCount))
+		for _, o := range h.ops {
+			b.WriteByte(byte(o.kind))
+			b.WriteString(o.line)
+			b.WriteByte('\n')
+		}
+	}
+
+	return b.String()
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines builds the shortest edit script with linear space variation of Myers' algorithm,
+// memory is proportional to the number of lines rather than their product
+func diffLines(from, to []string) []op {
+	// the furthest diagonal is reached after (len(from)+len(to)+1)/2 edits, one more on each side for k-1 and k+1
+	size := len(from) + len(to) + 4
+
+	d := differ{
+		from: from,
+		to:   to,
+		vf:   make([]int, size),
+		vb:   make([]int, size),
+		ops:  make([]op, 0, len(from)+len(to)),
+	}
+	d.compare(0, len(from), 0, len(to))
+
+	return d.ops
+}
+
+type differ struct {
+	from, to []string
+	// furthest reaching x of forward and backward paths, indexed by diagonal shifted by half of the length
+	vf, vb []int
+	ops    []op
+}
+
+// compare appends the edit script of from[fromLo:fromHi] and to[toLo:toHi], splitting them by the middle snake
+func (d *differ) compare(fromLo, fromHi, toLo, toHi int) {
+	for fromLo < fromHi && toLo < toHi && d.from[fromLo] == d.to[toLo] {
+		d.ops = append(d.ops, op{opEqual, d.from[fromLo]})
+		fromLo++
+		toLo++
+	}
+
+	suffix := 0
+	for fromHi > fromLo && toHi > toLo && d.from[fromHi-1] == d.to[toHi-1] {
+		fromHi--
+		toHi--
+		suffix++
+	}
+
+	switch {
+	case fromLo == fromHi:
+		for _, line := range d.to[toLo:toHi] {
+			d.ops = append(d.ops, op{opInsert, line})
+		}
+	case toLo == toHi:
+		for _, line := range d.from[fromLo:fromHi] {
+			d.ops = append(d.ops, op{opDelete, line})
+		}
+	default:
+		// both halves need at least one edit, since common prefix and suffix are stripped
+		x, y, u, v := d.middleSnake(fromLo, fromHi, toLo, toHi)
+
+		d.compare(fromLo, x, toLo, y)
+		for _, line := range d.from[x:u] {
+			d.ops = append(d.ops, op{opEqual, line})
+		}
+		d.compare(u, fromHi, v, toHi)
+	}
+
+	for _, line := range d.from[fromHi : fromHi+suffix] {
+		d.ops = append(d.ops, op{opEqual, line})
+	}
+}
+
+// middleSnake finds the snake (x, y) - (u, v) in the middle of the shortest edit script,
+// searching from both ends at once (Myers, 1986, section 4b).
+//
+// The shortest edit script has at most n+m edits, so forward and backward paths always overlap
+// after (n+m+1)/2 edits each. Leaving the loop means the implementation is broken, which is a programming
+// error rather than a property of the input, so it panics instead of returning a wrong diff.
+// The invariant is checked by TestDiffLinesMinimal.
+func (d *differ) middleSnake(fromLo, fromHi, toLo, toHi int) (x, y, u, v int) {
+	n, m := fromHi-fromLo, toHi-toLo
+	delta := n - m
+	odd := delta%2 != 0
+	offset := len(d.vf) / 2
+
+	d.vf[offset+1], d.vb[offset+1] = 0, 0
+
+	for D := 0; D <= (n+m+1)/2; D++ {
+		for k := -D; k <= D; k += 2 {
+			var x int
+			if k == -D || (k != D && d.vf[offset+k-1] < d.vf[offset+k+1]) {
+				x = d.vf[offset+k+1]
+			} else {
+				x = d.vf[offset+k-1] + 1
+			}
+
+			y := x - k
+			startX, startY := x, y
+
+			for x < n && y < m && d.from[fromLo+x] == d.to[toLo+y] {
+				x++
+				y++
+			}
+
+			d.vf[offset+k] = x
+
+			// backward path on the same diagonal has made D-1 edits
+			if kb := delta - k; odd && kb >= -(D-1) && kb <= D-1 && x+d.vb[offset+kb] >= n {
+				return fromLo + startX, toLo + startY, fromLo + x, toLo + y
+			}
+		}
+
+		for k := -D; k <= D; k += 2 {
+			var x int
+			if k == -D || (k != D && d.vb[offset+k-1] < d.vb[offset+k+1]) {
+				x = d.vb[offset+k+1]
+			} else {
+				x = d.vb[offset+k-1] + 1
+			}
+
+			y := x - k
+			startX, startY := x, y
+
+			for x < n && y < m && d.from[fromHi-1-x] == d.to[toHi-1-y] {
+				x++
+				y++
+			}
+
+			d.vb[offset+k] = x
+
+			// forward path on the same diagonal has made D edits
+			if kf := delta - k; !odd && kf >= -D && kf <= D && x+d.vf[offset+kf] >= n {
+				return fromHi - x, toHi - y, fromHi - startX, toHi - startY
+			}
+		}
+	}
+
+	panic("textdiff: middle snake not found")
+}
+
+type hunk struct {
+	fromStart, fromCount int
+	toStart, toCount     int
+	ops                  []op
+}
+
+// groupHunks splits the edit script into hunks of changes surrounded by ContextLines of unchanged lines,
+// changes separated by no more than 2*ContextLines unchanged lines share a hunk
+func groupHunks(ops []op) []hunk {
+	var hunks []hunk
+
+	// line numbers (0-based) in both texts before ops[i]
+	fromLine := make([]int, len(ops)+1)
+	toLine := make([]int, len(ops)+1)
+	for i, o := range ops {
+		fromLine[i+1], toLine[i+1] = fromLine[i], toLine[i]
+		if o.kind != opInsert {
+			fromLine[i+1]++
+		}
+		if o.kind != opDelete {
+			toLine[i+1]++
+		}
+	}
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == opEqual {
+			i++
+			continue
+		}
+
+		start := max(i-ContextLines, 0)
+
+		// extend the hunk while the next change is close enough
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+
+			next := end
+			for next < len(ops) && ops[next].kind == opEqual {
+				next++
+			}
+
+			if next == len(ops) || next-end > 2*ContextLines {
+				end = min(end+ContextLines, len(ops))
+				break
+			}
+
+			end = next
+		}
+
+		hunks = append(hunks, hunk{
+			fromStart: fromLine[start],
+			fromCount: fromLine[end] - fromLine[start],
+			toStart:   toLine[start],
+			toCount:   toLine[end] - toLine[start],
+			ops:       ops[start:end],
+		})
+
+		i = end
+	}
+
+	return hunks
+}
+
+// hunkRange formats hunk's range the way GNU diff does: the count is omitted for single lines,
+// and empty ranges start at the line preceding the hunk
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
@@ -0,0 +1,149 @@
+package textdiff
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	numbers := func(replace map[string]string) string {
+		var b strings.Builder
+		for i := 1; i <= 20; i++ {
+			line := strconv.Itoa(i)
+			if r, ok := replace[line]; ok {
+				line = r
+			}
+			b.WriteString(line + "\n")
+		}
+		return b.String()
+	}
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     string
+	}{
+		{
+			name: "empty",
+			want: "",
+		},
+		{
+			name: "identical",
+			from: "a\nb\nc\n",
+			to:   "a\nb\nc\n",
+			want: "",
+		},
+		{
+			name: "insert into empty",
+			to:   "a\nb\nc\n",
+			want: "--- from\n+++ to\n@@ -0,0 +1,3 @@\n+a\n+b\n+c\n",
+		},
+		{
+			name: "delete all",
+			from: "a\nb\nc\n",
+			want: "--- from\n+++ to\n@@ -1,3 +0,0 @@\n-a\n-b\n-c\n",
+		},
+		{
+			name: "insert only",
+			from: "a\nb\nc\n",
+			to:   "a\nb\nx\nc\n",
+			want: "--- from\n+++ to\n@@ -1,3 +1,4 @@\n a\n b\n+x\n c\n",
+		},
+		{
+			name: "delete only",
+			from: "a\nb\nc\n",
+			to:   "a\nc\n",
+			want: "--- from\n+++ to\n@@ -1,3 +1,2 @@\n a\n-b\n c\n",
+		},
+		{
+			name: "trailing newline is ignored",
+			from: "a\nb",
+			to:   "a\nb\n",
+			want: "",
+		},
+		{
+			name: "multiple hunks",
+			from: numbers(nil),
+			to:   numbers(map[string]string{"2": "two", "18": "eighteen"}),
+			want: "--- from\n+++ to\n" +
+				"@@ -1,5 +1,5 @@\n 1\n-2\n+two\n 3\n 4\n 5\n" +
+				"@@ -15,6 +15,6 @@\n 15\n 16\n 17\n-18\n+eighteen\n 19\n 20\n",
+		},
+		{
+			name: "close changes share a hunk",
+			from: numbers(nil),
+			to:   numbers(map[string]string{"5": "five", "11": "eleven"}),
+			want: "--- from\n+++ to\n" +
+				"@@ -2,13 +2,13 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n 9\n 10\n-11\n+eleven\n 12\n 13\n 14\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Unified("from", "to", tt.from, tt.to)
+			if got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDiffLinesMinimal checks that edit scripts turn one text into another with the fewest edits,
+// which also ensures middleSnake always finds the snake
+func TestDiffLinesMinimal(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	randomLines := func() []string {
+		lines := make([]string, rnd.Intn(30))
+		for i := range lines {
+			// a small alphabet makes many common lines
+			lines[i] = string(rune('a' + rnd.Intn(4)))
+		}
+		return lines
+	}
+
+	for i := 0; i < 5000; i++ {
+		from, to := randomLines(), randomLines()
+
+		var gotFrom, gotTo []string
+		edits := 0
+		for _, o := range diffLines(from, to) {
+			if o.kind != opInsert {
+				gotFrom = append(gotFrom, o.line)
+			}
+			if o.kind != opDelete {
+				gotTo = append(gotTo, o.line)
+			}
+			if o.kind != opEqual {
+				edits++
+			}
+		}
+
+		if strings.Join(gotFrom, "\n") != strings.Join(from, "\n") || strings.Join(gotTo, "\n") != strings.Join(to, "\n") {
+			t.Fatalf("edit script doesn't turn %q into %q", from, to)
+		}
+
+		if want := len(from) + len(to) - 2*lcsLength(from, to); edits != want {
+			t.Fatalf("%q -> %q: %d edits, want %d", from, to, edits, want)
+		}
+	}
+}
+
+// lcsLength is the reference length of the longest common subsequence
+func lcsLength(a, b []string) int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,14 @@
+// Package textnorm holds the canonical normalization of names compared regardless of formatting.
+package textnorm
+
+import (
+	"strings"
+
+	"golang.org/x/text/cases"
+)
+
+// Name joins words of the name separated by any Unicode whitespace with a single space and folds their case,
+// so names differing only in case or whitespaces are equal after normalization
+func Name(name string) string {
+	return cases.Fold().String(strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE missions
+    ADD COLUMN IF NOT EXISTS budget       INTEGER,
+    ADD COLUMN IF NOT EXISTS assigned_at  TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP;
+
+UPDATE missions SET assigned_at = updated_at WHERE assigned_cat_id IS NOT NULL;
+UPDATE missions SET completed_at = updated_at WHERE is_completed;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE missions
+    DROP COLUMN IF EXISTS budget,
+    DROP COLUMN IF EXISTS assigned_at,
+    DROP COLUMN IF EXISTS completed_at;
+-- +goose StatementEnd
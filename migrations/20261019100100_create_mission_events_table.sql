@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS mission_events
+(
+    id         SERIAL PRIMARY KEY,
+    mission_id INTEGER      NOT NULL,
+
+    type       VARCHAR(50)  NOT NULL,
+    actor      VARCHAR(100) NOT NULL,
+    payload    JSONB        NOT NULL DEFAULT '{}',
+
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY (mission_id) REFERENCES missions (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS mission_events_mission_id_idx ON mission_events (mission_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS mission_events;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE missions
+    ADD COLUMN IF NOT EXISTS reopened_at   TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS reopened_by   VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS reopen_reason TEXT;
+
+ALTER TABLE targets
+    ADD COLUMN IF NOT EXISTS reopened_at   TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS reopened_by   VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS reopen_reason TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE missions
+    DROP COLUMN IF EXISTS reopened_at,
+    DROP COLUMN IF EXISTS reopened_by,
+    DROP COLUMN IF EXISTS reopen_reason;
+
+ALTER TABLE targets
+    DROP COLUMN IF EXISTS reopened_at,
+    DROP COLUMN IF EXISTS reopened_by,
+    DROP COLUMN IF EXISTS reopen_reason;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- assignments are history, they are kept after the cat is deleted along with its name and salary at assignment time
+CREATE TABLE IF NOT EXISTS mission_assignments
+(
+    id            SERIAL PRIMARY KEY,
+    mission_id    INTEGER      NOT NULL,
+    cat_id        INTEGER      NOT NULL,
+    cat_name      VARCHAR(100) NOT NULL,
+    cat_salary    INTEGER      NOT NULL,
+
+    assigned_at   TIMESTAMP    NOT NULL DEFAULT NOW(),
+    unassigned_at TIMESTAMP,
+
+    FOREIGN KEY (mission_id) REFERENCES missions (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS mission_assignments_mission_id_idx ON mission_assignments (mission_id);
+CREATE INDEX IF NOT EXISTS mission_assignments_cat_id_idx ON mission_assignments (cat_id);
+
+INSERT INTO mission_assignments(mission_id, cat_id, cat_name, cat_salary, assigned_at)
+SELECT m.id, c.id, c.name, c.salary, COALESCE(m.assigned_at, m.updated_at)
+FROM missions m
+         JOIN cats c ON c.id = m.assigned_cat_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS mission_assignments;
+-- +goose StatementEnd
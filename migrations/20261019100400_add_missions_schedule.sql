@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE missions
+    ADD COLUMN IF NOT EXISTS starts_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS ends_at   TIMESTAMP,
+    ADD CONSTRAINT missions_schedule_check CHECK (starts_at IS NULL OR ends_at IS NULL OR ends_at > starts_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE missions
+    DROP CONSTRAINT IF EXISTS missions_schedule_check,
+    DROP COLUMN IF EXISTS starts_at,
+    DROP COLUMN IF EXISTS ends_at;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE missions
+    ADD COLUMN IF NOT EXISTS next_target_id INTEGER NOT NULL DEFAULT 1;
+
+UPDATE missions m
+SET next_target_id = COALESCE((SELECT MAX(t.id) FROM targets t WHERE t.mission_id = m.id), 0) + 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE missions
+    DROP COLUMN IF EXISTS next_target_id;
+-- +goose StatementEnd
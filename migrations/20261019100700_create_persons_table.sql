@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS persons
+(
+    id         SERIAL PRIMARY KEY,
+
+    name       VARCHAR(100) NOT NULL,
+    country    CHAR(2)      NOT NULL, -- ISO 3166-1 alpha-2
+
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE targets
+    ADD COLUMN IF NOT EXISTS person_id INTEGER REFERENCES persons (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS targets_person_id_idx ON targets (person_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE targets
+    DROP COLUMN IF EXISTS person_id;
+
+DROP TABLE IF EXISTS persons;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE targets
+    ADD COLUMN IF NOT EXISTS risk_level VARCHAR(10) NOT NULL DEFAULT 'low',
+    ADD COLUMN IF NOT EXISTS priority   SMALLINT    NOT NULL DEFAULT 3,
+    ADD CONSTRAINT targets_risk_level_check CHECK (risk_level IN ('low', 'medium', 'high')),
+    ADD CONSTRAINT targets_priority_check CHECK (priority BETWEEN 1 AND 5);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE targets
+    DROP CONSTRAINT IF EXISTS targets_risk_level_check,
+    DROP CONSTRAINT IF EXISTS targets_priority_check,
+    DROP COLUMN IF EXISTS risk_level,
+    DROP COLUMN IF EXISTS priority;
+-- +goose StatementEnd
@@ -0,0 +1,121 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/illiafox/spy-cat-test-assignment/app/pkg/textnorm"
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddTargetsNormalizedName, downAddTargetsNormalizedName)
+}
+
+// upAddTargetsNormalizedName adds names normalized by the application, see textnorm.Name, unique within a mission
+// and country. Existing duplicates are not renamed, the migration fails listing them to be resolved by hand.
+func upAddTargetsNormalizedName(ctx context.Context, tx *sql.Tx) error {
+	// case folding may make names longer
+	_, err := tx.ExecContext(ctx, `ALTER TABLE targets ADD COLUMN IF NOT EXISTS normalized_name TEXT`)
+	if err != nil {
+		return fmt.Errorf("add column: %w", err)
+	}
+
+	type target struct {
+		missionID, id int
+		name, country string
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT mission_id, id, name, country FROM targets ORDER BY mission_id, id`)
+	if err != nil {
+		return fmt.Errorf("query targets: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []target
+	for rows.Next() {
+		var t target
+		if err = rows.Scan(&t.missionID, &t.id, &t.name, &t.country); err != nil {
+			return fmt.Errorf("scan target: %w", err)
+		}
+
+		targets = append(targets, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("iterate targets: %w", err)
+	}
+
+	type key struct {
+		missionID     int
+		name, country string
+	}
+
+	var (
+		keys       []key
+		duplicates = make(map[key][]target, len(targets))
+	)
+
+	for _, t := range targets {
+		normalized := textnorm.Name(t.name)
+
+		k := key{t.missionID, normalized, t.country}
+		if _, ok := duplicates[k]; !ok {
+			keys = append(keys, k)
+		}
+		duplicates[k] = append(duplicates[k], t)
+
+		_, err = tx.ExecContext(ctx, `UPDATE targets SET normalized_name = $3 WHERE mission_id = $1 AND id = $2`,
+			t.missionID, t.id, normalized)
+		if err != nil {
+			return fmt.Errorf("update target %d of mission %d: %w", t.id, t.missionID, err)
+		}
+	}
+
+	var conflicts []string
+	for _, k := range keys {
+		if len(duplicates[k]) < 2 {
+			continue
+		}
+
+		names := make([]string, len(duplicates[k]))
+		for i, t := range duplicates[k] {
+			names[i] = fmt.Sprintf("%d %q", t.id, t.name)
+		}
+
+		conflicts = append(conflicts, fmt.Sprintf("mission %d, country %s: targets %s",
+			k.missionID, k.country, strings.Join(names, ", ")))
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("targets with the same name within a mission and country must be renamed or deleted "+
+			"before migrating:\n%s", strings.Join(conflicts, "\n"))
+	}
+
+	for _, query := range []string{
+		`ALTER TABLE targets ALTER COLUMN normalized_name SET NOT NULL`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS targets_mission_id_normalized_name_country_key
+			ON targets (mission_id, normalized_name, country)`,
+	} {
+		if _, err = tx.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("exec '%s': %w", query, err)
+		}
+	}
+
+	return nil
+}
+
+func downAddTargetsNormalizedName(ctx context.Context, tx *sql.Tx) error {
+	for _, query := range []string{
+		`DROP INDEX IF EXISTS targets_mission_id_normalized_name_country_key`,
+		`ALTER TABLE targets DROP COLUMN IF EXISTS normalized_name`,
+	} {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("exec '%s': %w", query, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS note_revisions
+(
+    id         SERIAL PRIMARY KEY,
+    -- not a foreign key, revisions are kept after the note is deleted
+    note_id    INTEGER      NOT NULL,
+    mission_id INTEGER      NOT NULL,
+    target_id  INTEGER      NOT NULL,
+
+    revision   INTEGER      NOT NULL,
+    content    TEXT         NOT NULL,
+    editor     VARCHAR(100) NOT NULL,
+
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW(),
+
+    UNIQUE (note_id, revision),
+    FOREIGN KEY (mission_id, target_id) REFERENCES targets (mission_id, id) ON DELETE CASCADE
+);
+
+INSERT INTO note_revisions(note_id, mission_id, target_id, revision, content, editor, created_at)
+SELECT id, mission_id, target_id, 1, content, 'anonymous', created_at
+FROM notes;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS note_revisions;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE notes
+    ADD COLUMN IF NOT EXISTS author_cat_id INTEGER REFERENCES cats (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS notes_author_cat_id_idx ON notes (author_cat_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE notes
+    DROP COLUMN IF EXISTS author_cat_id;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS note_attachments
+(
+    id           SERIAL PRIMARY KEY,
+    note_id      INTEGER      NOT NULL REFERENCES notes (id) ON DELETE CASCADE,
+    mission_id   INTEGER      NOT NULL,
+    target_id    INTEGER      NOT NULL,
+
+    file_name    VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size         BIGINT       NOT NULL CHECK ( size >= 0 ),
+    -- key of the contents in the blob store
+    storage_key  VARCHAR(255) NOT NULL UNIQUE,
+
+    created_at   TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS note_attachments_note_id_idx ON note_attachments (note_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS note_attachments;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+-- contents may be encrypted, so the search vector is calculated by the application from the plain text
+ALTER TABLE notes
+    ALTER COLUMN search_vector DROP EXPRESSION;
+
+-- key_id is the master key the data key is wrapped with, contents are in plain text if it's null
+ALTER TABLE notes
+    ADD COLUMN IF NOT EXISTS data_key BYTEA,
+    ADD COLUMN IF NOT EXISTS key_id   VARCHAR(64),
+    ADD CONSTRAINT notes_encryption_check CHECK ( (data_key IS NULL) = (key_id IS NULL) );
+
+ALTER TABLE note_revisions
+    ADD COLUMN IF NOT EXISTS data_key BYTEA,
+    ADD COLUMN IF NOT EXISTS key_id   VARCHAR(64),
+    ADD CONSTRAINT note_revisions_encryption_check CHECK ( (data_key IS NULL) = (key_id IS NULL) );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE note_revisions
+    DROP CONSTRAINT IF EXISTS note_revisions_encryption_check,
+    DROP COLUMN IF EXISTS data_key,
+    DROP COLUMN IF EXISTS key_id;
+
+ALTER TABLE notes
+    DROP CONSTRAINT IF EXISTS notes_encryption_check,
+    DROP COLUMN IF EXISTS data_key,
+    DROP COLUMN IF EXISTS key_id;
+
+ALTER TABLE notes
+    DROP COLUMN IF EXISTS search_vector;
+
+ALTER TABLE notes
+    ADD COLUMN search_vector TSVECTOR
+        GENERATED ALWAYS AS (TO_TSVECTOR('english', content)) STORED;
+
+CREATE INDEX IF NOT EXISTS notes_search_vector_idx ON notes USING GIN (search_vector);
+-- +goose StatementEnd
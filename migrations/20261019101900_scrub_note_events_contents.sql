@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- note events used to keep contents of notes in plain text, now they are kept only by notes and revisions,
+-- which are encrypted if keys are configured. Old rows stay in table files until vacuumed.
+UPDATE mission_events
+SET payload = payload - 'content' - 'previous_content'
+WHERE type IN ('note_updated', 'note_deleted')
+  AND payload ?| ARRAY ['content', 'previous_content'];
+-- +goose StatementEnd
+
+-- +goose Down
+-- contents are gone, there is nothing to restore